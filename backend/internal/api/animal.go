@@ -3,32 +3,35 @@ package api
 import (
 	"context"
 	"encoding/json"
-	"fmt"
-	"io"
 	"net/http"
-	"os"
 	"path"
-	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/dspeirs7/animals/internal/domain"
+	"github.com/dspeirs7/animals/internal/middleware"
 )
 
-func (a *api) getCats(w http.ResponseWriter, r *http.Request) {
+func (a *api) getAnimals(w http.ResponseWriter, r *http.Request) {
 	switch r.Method {
 	case http.MethodGet:
 		ctx, cancel := context.WithCancel(r.Context())
 		defer cancel()
 
-		results, err := a.animalRepo.GetAllCats(ctx)
+		query, err := parseAnimalQuery(r.URL.Query())
 		if err != nil {
-			a.errorResponse(w, r, http.StatusInternalServerError, err)
+			a.errorResponse(w, r, http.StatusBadRequest, err)
 			return
 		}
 
-		w.WriteHeader(http.StatusOK)
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(results)
+		page, err := a.findAnimals(ctx, query)
+		if err != nil {
+			a.errorResponse(w, r, queryErrorStatus(err), err)
+			return
+		}
+
+		a.writeAnimalPage(w, r, page)
 	case http.MethodOptions:
 		w.Header().Set("Allow", "GET, OPTIONS")
 		w.WriteHeader(http.StatusNoContent)
@@ -38,21 +41,35 @@ func (a *api) getCats(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-func (a *api) getChickens(w http.ResponseWriter, r *http.Request) {
+// getAnimalsOfType serves the fixed /api/cats style routes, which predate
+// the type registry.
+func (a *api) getAnimalsOfType(animalType domain.AnimalType) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		a.animalsOfType(w, r, animalType)
+	}
+}
+
+func (a *api) animalsOfType(w http.ResponseWriter, r *http.Request, animalType domain.AnimalType) {
 	switch r.Method {
 	case http.MethodGet:
 		ctx, cancel := context.WithCancel(r.Context())
 		defer cancel()
 
-		results, err := a.animalRepo.GetAllChickens(ctx)
+		query, err := parseAnimalQuery(r.URL.Query())
 		if err != nil {
-			a.errorResponse(w, r, http.StatusInternalServerError, err)
+			a.errorResponse(w, r, http.StatusBadRequest, err)
 			return
 		}
 
-		w.WriteHeader(http.StatusOK)
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(results)
+		query.Types = []domain.AnimalType{animalType}
+
+		page, err := a.findAnimals(ctx, query)
+		if err != nil {
+			a.errorResponse(w, r, queryErrorStatus(err), err)
+			return
+		}
+
+		a.writeAnimalPage(w, r, page)
 	case http.MethodOptions:
 		w.Header().Set("Allow", "GET, OPTIONS")
 		w.WriteHeader(http.StatusNoContent)
@@ -62,28 +79,40 @@ func (a *api) getChickens(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-func (a *api) getDogs(w http.ResponseWriter, r *http.Request) {
-	switch r.Method {
-	case http.MethodGet:
-		ctx, cancel := context.WithCancel(r.Context())
-		defer cancel()
-
-		results, err := a.animalRepo.GetAllDogs(ctx)
+// findAnimals resolves the parts of query that live outside the animals
+// collection, medication and custom field definitions, before running it.
+func (a *api) findAnimals(ctx context.Context, query domain.AnimalQuery) (*domain.AnimalPage, error) {
+	if query.OnMedication != nil {
+		ids, err := a.medicalRepo.AnimalsOnMedication(ctx, time.Now())
 		if err != nil {
-			a.errorResponse(w, r, http.StatusInternalServerError, err)
-			return
+			return nil, err
 		}
 
-		w.WriteHeader(http.StatusOK)
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(results)
-	case http.MethodOptions:
-		w.Header().Set("Allow", "GET, OPTIONS")
-		w.WriteHeader(http.StatusNoContent)
-	default:
-		w.Header().Set("Allow", "GET, OPTIONS")
-		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		if *query.OnMedication {
+			query.Ids = ids
+		} else {
+			query.ExcludeIds = ids
+		}
+	}
+
+	if len(query.Attributes) > 0 {
+		if err := a.resolveAttributeFilters(ctx, &query); err != nil {
+			return nil, err
+		}
+	}
+
+	return a.animalRepo.Find(ctx, query)
+}
+
+// writeAnimalPage sends a page of animals as a bare array, with the total
+// and the cursor of the next page in headers.
+func (a *api) writeAnimalPage(w http.ResponseWriter, r *http.Request, page *domain.AnimalPage) {
+	w.Header().Set("X-Total-Count", strconv.FormatInt(page.Total, 10))
+	if page.NextCursor != "" {
+		w.Header().Set("X-Next-Cursor", page.NextCursor)
 	}
+
+	a.jsonResponse(w, r, http.StatusOK, page.Animals)
 }
 
 func (a *api) handleAnimal(w http.ResponseWriter, r *http.Request) {
@@ -92,6 +121,7 @@ func (a *api) handleAnimal(w http.ResponseWriter, r *http.Request) {
 
 	id := path.Base(r.URL.Path)
 	animal := r.Context().Value("animal").(*domain.Animal)
+	existing := animal
 
 	switch r.Method {
 	case http.MethodGet:
@@ -110,9 +140,43 @@ func (a *api) handleAnimal(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
+		if err := animal.Validate(); err != nil {
+			a.errorResponse(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		species, err := a.validateType(ctx, animal.Type, true)
+		if err != nil {
+			a.errorResponse(w, r, speciesErrorStatus(err), err)
+			return
+		}
+
+		if err := a.validateAttributes(ctx, &animal); err != nil {
+			a.errorResponse(w, r, fieldErrorStatus(err), err)
+			return
+		}
+
+		if err := a.validateBreed(ctx, &animal); err != nil {
+			a.errorResponse(w, r, breedErrorStatus(err), err)
+			return
+		}
+
+		if err := a.validateLineage(ctx, nil, &animal); err != nil {
+			a.errorResponse(w, r, animalLineageStatus(err), err)
+			return
+		}
+
+		if err := a.startStatus(ctx, &animal); err != nil {
+			a.errorResponse(w, r, statusErrorStatus(err), err)
+			return
+		}
+
+		a.withDefaultProtocol(ctx, species, &animal)
+		animal.DeletedAt, animal.DeletedBy = nil, ""
+
 		result, err := a.animalRepo.Insert(ctx, animal)
 		if err != nil {
-			a.errorResponse(w, r, http.StatusInternalServerError, err)
+			a.errorResponse(w, r, identifierErrorStatus(err), err)
 			return
 		}
 
@@ -128,20 +192,57 @@ func (a *api) handleAnimal(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
+		if err := animal.Validate(); err != nil {
+			a.errorResponse(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		if _, err := a.validateType(ctx, animal.Type, animal.Type != existing.Type); err != nil {
+			a.errorResponse(w, r, speciesErrorStatus(err), err)
+			return
+		}
+
+		if err := a.validateAttributes(ctx, &animal); err != nil {
+			a.errorResponse(w, r, fieldErrorStatus(err), err)
+			return
+		}
+
+		if err := a.validateBreed(ctx, &animal); err != nil {
+			a.errorResponse(w, r, breedErrorStatus(err), err)
+			return
+		}
+
+		if err := a.validateLineage(ctx, existing, &animal); err != nil {
+			a.errorResponse(w, r, animalLineageStatus(err), err)
+			return
+		}
+
+		// Status only changes through /api/animal/{id}/status, and animals
+		// only reach the trash through DELETE.
+		animal.Status, animal.StatusHistory, animal.ContactId = existing.Status, existing.StatusHistory, existing.ContactId
+		animal.DeletedAt, animal.DeletedBy = nil, ""
+
+		// Photos only change through /api/photos, and weights through
+		// /api/animal/{id}/weights.
+		animal.Photos, animal.Images, animal.ImageUrl = existing.Photos, existing.Images, existing.ImageUrl
+		animal.Weights = existing.Weights
+
 		if err := a.animalRepo.Update(ctx, id, animal); err != nil {
-			a.errorResponse(w, r, http.StatusInternalServerError, err)
+			a.errorResponse(w, r, identifierErrorStatus(err), err)
 			return
 		}
 
 		w.WriteHeader(http.StatusOK)
 	case http.MethodDelete:
-		if err := a.animalRepo.Delete(ctx, id); err != nil {
-			a.errorResponse(w, r, http.StatusInternalServerError, err)
-			return
+		// Deleted animals go to the trash; the trash-purge job removes them for good.
+		var by string
+		if session, ok := middleware.SessionFromContext(ctx); ok {
+			by = session.Username
 		}
 
-		if animal.ImageUrl != "" {
-			_ = os.Remove(fmt.Sprintf("./%s", animal.ImageUrl))
+		if err := a.animalRepo.Trash(ctx, existing.Id, time.Now(), by); err != nil {
+			a.errorResponse(w, r, trashErrorStatus(err), err)
+			return
 		}
 
 		w.WriteHeader(http.StatusOK)
@@ -171,10 +272,24 @@ func (a *api) addVaccinations(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
+		animal, err := a.animalRepo.GetById(ctx, id)
+		if err != nil {
+			a.errorResponse(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		vaccinations, err = a.withBoosters(ctx, animal, vaccinations)
+		if err != nil {
+			a.errorResponse(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
 		if err := a.animalRepo.AddVaccinations(ctx, id, vaccinations); err != nil {
 			a.errorResponse(w, r, http.StatusInternalServerError, err)
 			return
 		}
+
+		a.jsonResponse(w, r, http.StatusOK, vaccinations)
 	case http.MethodOptions:
 		w.Header().Set("Allow", "POST, OPTIONS")
 		w.WriteHeader(http.StatusNoContent)
@@ -214,55 +329,36 @@ func (a *api) deleteVaccination(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-func (a *api) uploadImage(w http.ResponseWriter, r *http.Request) {
-	ctx, cancel := context.WithCancel(r.Context())
-	defer cancel()
-
-	id := path.Base(r.URL.Path)
-
-	r.ParseMultipartForm(10 << 20)
-
-	animal := r.Context().Value("animal").(*domain.Animal)
-
-	if animal.ImageUrl != "" {
-		_ = os.Remove(fmt.Sprintf("./%s", animal.ImageUrl))
-	}
-
-	file, handler, err := r.FormFile("image")
-	if err != nil {
-		a.errorResponse(w, r, http.StatusInternalServerError, err)
-		return
+// animalRoutes dispatches /api/animal/{id}/{resource} sub-resources, and
+// everything else to handleAnimal.
+func (a *api) animalRoutes() http.Handler {
+	resources := map[string]http.Handler{
+		"weights":     http.HandlerFunc(a.getWeights),
+		"medical":     http.HandlerFunc(a.animalMedical),
+		"feeding":     http.HandlerFunc(a.animalFeedingPlans),
+		"pedigree":    http.HandlerFunc(a.getPedigree),
+		"descendants": http.HandlerFunc(a.getDescendants),
+		"status":      http.HandlerFunc(a.animalStatus),
 	}
 
-	defer file.Close()
+	animal := http.HandlerFunc(a.handleAnimal)
 
-	if err := os.MkdirAll(filepath.Join(".", "images"), os.ModePerm); err != nil {
-		a.errorResponse(w, r, http.StatusInternalServerError, err)
-		return
-	}
-
-	fileName := fmt.Sprintf("images/%d%s", time.Now().UnixNano(), filepath.Ext(handler.Filename))
-
-	dst, err := os.Create(fmt.Sprintf("./%s", fileName))
-	if err != nil {
-		a.errorResponse(w, r, http.StatusInternalServerError, err)
-		return
-	}
-
-	defer dst.Close()
-
-	_, err = io.Copy(dst, file)
-	if err != nil {
-		a.errorResponse(w, r, http.StatusInternalServerError, err)
-	}
+	return a.AnimalCtx(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+		if len(parts) == 4 {
+			if resource, ok := resources[parts[3]]; ok {
+				resource.ServeHTTP(w, r)
+				return
+			}
+		}
 
-	if err := a.animalRepo.UpdateImageUrl(ctx, id, fileName); err != nil {
-		a.errorResponse(w, r, http.StatusInternalServerError, err)
-	}
+		if len(parts) > 3 {
+			http.NotFound(w, r)
+			return
+		}
 
-	w.WriteHeader(http.StatusOK)
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(domain.Animal{ImageUrl: fileName})
+		animal.ServeHTTP(w, r)
+	}))
 }
 
 func (a *api) AnimalCtx(next http.Handler) http.Handler {
@@ -275,7 +371,7 @@ func (a *api) AnimalCtx(next http.Handler) http.Handler {
 		ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
 		defer cancel()
 
-		id := path.Base(r.URL.Path)
+		id := animalPathId(r.URL.Path)
 
 		animal, err := a.animalRepo.GetById(ctx, id)
 		if err != nil {
@@ -287,3 +383,13 @@ func (a *api) AnimalCtx(next http.Handler) http.Handler {
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
+
+// animalPathId returns the id in /api/{route}/{id} and
+// /api/{route}/{id}/{resource} paths.
+func animalPathId(p string) string {
+	parts := strings.Split(strings.Trim(p, "/"), "/")
+	if len(parts) >= 3 {
+		return parts[2]
+	}
+	return path.Base(p)
+}
@@ -7,10 +7,14 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"strconv"
+	"time"
 
 	"github.com/dspeirs7/animals/internal/domain"
 	"github.com/dspeirs7/animals/internal/middleware"
 	"github.com/dspeirs7/animals/internal/repository"
+	"github.com/dspeirs7/animals/internal/scheduler"
+	"github.com/dspeirs7/animals/internal/storage"
 	"github.com/rs/cors"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.uber.org/zap"
@@ -20,8 +24,28 @@ type api struct {
 	logger   *zap.Logger
 	dbClient *mongo.Client
 
-	animalRepo domain.AnimalRepository
-	userRepo   domain.UserRepository
+	animalRepo   domain.AnimalRepository
+	userRepo     domain.UserRepository
+	protocolRepo domain.ProtocolRepository
+	eggRepo      domain.EggRepository
+	medicalRepo  domain.MedicalRepository
+	doseRepo     domain.DoseRepository
+	feedingRepo  domain.FeedingRepository
+	litterRepo   domain.LitterRepository
+	breedRepo    domain.BreedRepository
+	speciesRepo  domain.SpeciesRepository
+	fieldRepo    domain.FieldRepository
+	contactRepo  domain.ContactRepository
+	lockRepo     domain.LockRepository
+	sessions     domain.SessionStore
+	imageStore   domain.ImageStore
+
+	maxUploadBytes int64
+
+	// location is the farm's time zone, which decides where "today" starts.
+	location *time.Location
+
+	scheduler *scheduler.Scheduler
 }
 
 func NewAPI(ctx context.Context, logger *zap.Logger) *api {
@@ -30,14 +54,112 @@ func NewAPI(ctx context.Context, logger *zap.Logger) *api {
 
 	animalRepo := repository.NewAnimalRepository(db.Collection("animals"))
 	userRepo := repository.NewUserRepository(db.Collection("users"))
+	protocolRepo := repository.NewProtocolRepository(db.Collection("protocols"))
+	litterRepo := repository.NewLitterRepository(db.Collection("litters"))
+	contactRepo := repository.NewContactRepository(db.Collection("contacts"))
+
+	eggRepo, err := repository.NewEggRepository(ctx, db.Collection("eggs"))
+	if err != nil {
+		logger.Fatal("error creating egg repository", zap.Error(err))
+	}
+
+	medicalRepo, err := repository.NewMedicalRepository(ctx, db.Collection("medical"))
+	if err != nil {
+		logger.Fatal("error creating medical repository", zap.Error(err))
+	}
+
+	doseRepo, err := repository.NewDoseRepository(ctx, db.Collection("doses"))
+	if err != nil {
+		logger.Fatal("error creating dose repository", zap.Error(err))
+	}
+
+	feedingRepo, err := repository.NewFeedingRepository(ctx, db.Collection("feedingPlans"), db.Collection("feedings"))
+	if err != nil {
+		logger.Fatal("error creating feeding repository", zap.Error(err))
+	}
+
+	breedRepo, err := repository.NewBreedRepository(ctx, db.Collection("breeds"))
+	if err != nil {
+		logger.Fatal("error creating breed repository", zap.Error(err))
+	}
+
+	speciesRepo, err := repository.NewSpeciesRepository(ctx, db.Collection("types"))
+	if err != nil {
+		logger.Fatal("error creating animal type repository", zap.Error(err))
+	}
+
+	fieldRepo, err := repository.NewFieldRepository(ctx, db.Collection("fields"))
+	if err != nil {
+		logger.Fatal("error creating field repository", zap.Error(err))
+	}
 
-	return &api{
+	lockRepo, err := repository.NewLockRepository(ctx, db.Collection("locks"))
+	if err != nil {
+		logger.Fatal("error creating lock repository", zap.Error(err))
+	}
+
+	var sessions domain.SessionStore
+	if os.Getenv("SESSION_STORE") == "memory" {
+		sessions = repository.NewMemorySessionStore()
+	} else {
+		var err error
+		sessions, err = repository.NewMongoSessionStore(ctx, db.Collection("sessions"))
+		if err != nil {
+			logger.Fatal("error creating session store", zap.Error(err))
+		}
+	}
+
+	imageStore, err := storage.NewImageStoreFromEnv()
+	if err != nil {
+		logger.Fatal("error creating image store", zap.Error(err))
+	}
+
+	a := &api{
 		logger:   logger,
 		dbClient: dbClient,
 
-		animalRepo: animalRepo,
-		userRepo:   userRepo,
+		animalRepo:   animalRepo,
+		userRepo:     userRepo,
+		protocolRepo: protocolRepo,
+		eggRepo:      eggRepo,
+		medicalRepo:  medicalRepo,
+		doseRepo:     doseRepo,
+		feedingRepo:  feedingRepo,
+		litterRepo:   litterRepo,
+		breedRepo:    breedRepo,
+		speciesRepo:  speciesRepo,
+		fieldRepo:    fieldRepo,
+		contactRepo:  contactRepo,
+		lockRepo:     lockRepo,
+		sessions:     sessions,
+		imageStore:   imageStore,
+
+		maxUploadBytes: defaultMaxUploadBytes,
+
+		location: time.Local,
+
+		scheduler: scheduler.New(logger.Named("scheduler")),
+	}
+
+	if v := os.Getenv("UPLOAD_MAX_BYTES"); v != "" {
+		limit, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || limit <= 0 {
+			logger.Fatal("invalid UPLOAD_MAX_BYTES", zap.String("value", v))
+		}
+		a.maxUploadBytes = limit
+	}
+
+	if v := os.Getenv("FARM_TIMEZONE"); v != "" {
+		location, err := time.LoadLocation(v)
+		if err != nil {
+			logger.Fatal("invalid FARM_TIMEZONE", zap.String("value", v), zap.Error(err))
+		}
+		a.location = location
 	}
+
+	a.registerJobs()
+
+	return a
 }
 
 func (a *api) Server(port int) *http.Server {
@@ -49,6 +171,7 @@ func (a *api) Server(port int) *http.Server {
 		handler = cors.New(cors.Options{
 			AllowedOrigins:   []string{"http://localhost:4200"},
 			AllowCredentials: true,
+			ExposedHeaders:   []string{"X-Total-Count", "X-Next-Cursor"},
 		}).Handler(a.Routes())
 	} else {
 		handler = a.Routes()
@@ -67,17 +190,63 @@ func (a *api) Routes() *http.ServeMux {
 
 	r.HandleFunc("/auth/login", a.login)
 	r.HandleFunc("/auth/logout", a.logout)
+	r.Handle("/auth/password", middleware.Logger(middleware.Session(a.sessions, http.HandlerFunc(a.changePassword))))
+	r.Handle("/auth/calendar-token", middleware.Logger(middleware.Session(a.sessions, http.HandlerFunc(a.calendarToken))))
+
+	r.Handle("/api/jobs", middleware.AdminMiddleware(a.sessions, http.HandlerFunc(a.getJobs)))
+	r.Handle("/api/jobs/run/", middleware.AdminMiddleware(a.sessions, http.HandlerFunc(a.runJob)))
+	r.Handle("/api/users", middleware.AdminMiddleware(a.sessions, http.HandlerFunc(a.getUsers)))
+	r.Handle("/api/user/", middleware.AdminMiddleware(a.sessions, http.HandlerFunc(a.handleUser)))
 
-	r.Handle("/api/image/", middleware.CommonMiddleware(a.AnimalCtx(http.HandlerFunc(a.uploadImage))))
-	r.Handle("/api/cats", middleware.CommonMiddleware(http.HandlerFunc(a.getCats)))
-	r.Handle("/api/chickens", middleware.CommonMiddleware(http.HandlerFunc(a.getChickens)))
-	r.Handle("/api/dogs", middleware.CommonMiddleware(http.HandlerFunc(a.getDogs)))
-	r.Handle("/api/animal/", middleware.CommonMiddleware((a.AnimalCtx(http.HandlerFunc(a.handleAnimal)))))
-	r.Handle("/api/vaccination/add/", middleware.CommonMiddleware(http.HandlerFunc(a.addVaccinations)))
-	r.Handle("/api/vaccination/delete/", middleware.CommonMiddleware(http.HandlerFunc(a.deleteVaccination)))
+	r.Handle("/api/image/", middleware.CommonMiddleware(a.sessions, a.AnimalCtx(http.HandlerFunc(a.uploadImage))))
+	r.Handle("/api/photos/", middleware.CommonMiddleware(a.sessions, http.HandlerFunc(a.handlePhotos)))
+	r.Handle("/api/animals", middleware.CommonMiddleware(a.sessions, http.HandlerFunc(a.getAnimals)))
+	r.Handle("/api/types", middleware.CatalogMiddleware(a.sessions, http.HandlerFunc(a.getTypes)))
+	r.Handle("/api/type/", middleware.CatalogMiddleware(a.sessions, http.HandlerFunc(a.handleType)))
+	r.Handle("/api/fields", middleware.CatalogMiddleware(a.sessions, http.HandlerFunc(a.getFields)))
+	r.Handle("/api/field/", middleware.CatalogMiddleware(a.sessions, http.HandlerFunc(a.handleField)))
+	r.Handle("/api/types/", middleware.CommonMiddleware(a.sessions, http.HandlerFunc(a.getTypeAnimals)))
+	// Aliases of /api/types/{slug}/animals kept for older clients.
+	r.Handle("/api/cats", middleware.CommonMiddleware(a.sessions, a.getAnimalsOfType(domain.CatType)))
+	r.Handle("/api/chickens", middleware.CommonMiddleware(a.sessions, a.getAnimalsOfType(domain.ChickenType)))
+	r.Handle("/api/dogs", middleware.CommonMiddleware(a.sessions, a.getAnimalsOfType(domain.DogType)))
+	r.Handle("/api/animal/", middleware.CommonMiddleware(a.sessions, a.animalRoutes()))
+	r.Handle("/api/breeds", middleware.CatalogMiddleware(a.sessions, http.HandlerFunc(a.getBreeds)))
+	r.Handle("/api/breed/", middleware.CatalogMiddleware(a.sessions, http.HandlerFunc(a.handleBreed)))
+	r.Handle("/api/contacts", middleware.CommonMiddleware(a.sessions, http.HandlerFunc(a.getContacts)))
+	r.Handle("/api/contact/", middleware.CommonMiddleware(a.sessions, http.HandlerFunc(a.handleContact)))
+	r.Handle("/api/trash", middleware.CommonMiddleware(a.sessions, http.HandlerFunc(a.getTrash)))
+	r.Handle("/api/trash/", middleware.CommonMiddleware(a.sessions, http.HandlerFunc(a.restoreAnimal)))
+	r.Handle("/api/lookup", middleware.CommonMiddleware(a.sessions, http.HandlerFunc(a.lookupIdentifier)))
+	r.Handle("/api/litters", middleware.CommonMiddleware(a.sessions, http.HandlerFunc(a.getLitters)))
+	r.Handle("/api/litter/", middleware.CommonMiddleware(a.sessions, http.HandlerFunc(a.handleLitter)))
+	r.Handle("/api/vaccination/add/", middleware.CommonMiddleware(a.sessions, http.HandlerFunc(a.addVaccinations)))
+	r.Handle("/api/vaccination/delete/", middleware.CommonMiddleware(a.sessions, http.HandlerFunc(a.deleteVaccination)))
+	r.Handle("/api/calendar/vaccinations.ics", middleware.CommonMiddleware(a.sessions, http.HandlerFunc(a.getVaccinationCalendar)))
+	r.Handle("/api/protocols", middleware.CatalogMiddleware(a.sessions, http.HandlerFunc(a.getProtocols)))
+	r.Handle("/api/protocol/", middleware.CatalogMiddleware(a.sessions, http.HandlerFunc(a.handleProtocol)))
+	r.Handle("/api/protocol/backfill/", middleware.CatalogMiddleware(a.sessions, http.HandlerFunc(a.backfillProtocol)))
+	r.Handle("/api/weight/add/", middleware.CommonMiddleware(a.sessions, http.HandlerFunc(a.addWeights)))
+	r.Handle("/api/weight/delete/", middleware.CommonMiddleware(a.sessions, http.HandlerFunc(a.deleteWeight)))
+	r.Handle("/api/eggs", middleware.CommonMiddleware(a.sessions, http.HandlerFunc(a.getEggLogs)))
+	r.Handle("/api/eggs/", middleware.CommonMiddleware(a.sessions, http.HandlerFunc(a.handleEggLog)))
+	r.Handle("/api/eggs/stats", middleware.CommonMiddleware(a.sessions, http.HandlerFunc(a.getEggStats)))
+	r.Handle("/api/eggs/streaks", middleware.CommonMiddleware(a.sessions, http.HandlerFunc(a.getEggStreaks)))
+	r.Handle("/api/medical/", middleware.CommonMiddleware(a.sessions, http.HandlerFunc(a.handleMedicalRecord)))
+	r.Handle("/api/medications/active", middleware.CommonMiddleware(a.sessions, http.HandlerFunc(a.getActiveMedications)))
+	r.Handle("/api/doses/today", middleware.CommonMiddleware(a.sessions, http.HandlerFunc(a.getDosesToday)))
+	r.Handle("/api/doses/missed", middleware.CommonMiddleware(a.sessions, http.HandlerFunc(a.getDosesMissed)))
+	r.Handle("/api/doses/", middleware.CommonMiddleware(a.sessions, http.HandlerFunc(a.administerDose)))
+	r.Handle("/api/feeding/plans", middleware.CommonMiddleware(a.sessions, http.HandlerFunc(a.getFeedingPlans)))
+	r.Handle("/api/feeding/plan/", middleware.CommonMiddleware(a.sessions, http.HandlerFunc(a.handleFeedingPlan)))
+	r.Handle("/api/feedings", middleware.CommonMiddleware(a.sessions, http.HandlerFunc(a.getFeedings)))
+	r.Handle("/api/feedings/today", middleware.CommonMiddleware(a.sessions, http.HandlerFunc(a.getFeedingsToday)))
+	r.Handle("/api/feedings/outstanding", middleware.CommonMiddleware(a.sessions, http.HandlerFunc(a.getFeedingsOutstanding)))
+	r.Handle("/api/feedings/", middleware.CommonMiddleware(a.sessions, http.HandlerFunc(a.feed)))
+	r.Handle("/api/vaccinations/due", middleware.CommonMiddleware(a.sessions, http.HandlerFunc(a.getVaccinationsDue)))
+	r.Handle("/api/vaccinations/overdue", middleware.CommonMiddleware(a.sessions, http.HandlerFunc(a.getVaccinationsOverdue)))
 
-	fs := http.FileServer(http.Dir("images"))
-	r.Handle("/images/", http.StripPrefix("/images/", fs))
+	r.Handle("/images/", http.StripPrefix("/images/", http.HandlerFunc(a.serveImage)))
 
 	if env == "production" || env == "dev" {
 		r.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
@@ -0,0 +1,197 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"path"
+	"strconv"
+
+	"github.com/dspeirs7/animals/internal/domain"
+)
+
+func (a *api) getBreeds(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	switch r.Method {
+	case http.MethodGet:
+		var animalType domain.AnimalType
+		if v := r.URL.Query().Get("type"); v != "" {
+			t, err := strconv.Atoi(v)
+			if err != nil {
+				a.errorResponse(w, r, http.StatusBadRequest, err)
+				return
+			}
+			animalType = domain.AnimalType(t)
+		}
+
+		breeds, err := a.breedRepo.List(ctx, animalType)
+		if err != nil {
+			a.errorResponse(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		a.jsonResponse(w, r, http.StatusOK, breeds)
+	case http.MethodPost:
+		var breed domain.Breed
+		if err := json.NewDecoder(r.Body).Decode(&breed); err != nil {
+			a.errorResponse(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		if err := breed.Validate(); err != nil {
+			a.errorResponse(w, r, breedErrorStatus(err), err)
+			return
+		}
+
+		if _, err := a.validateType(ctx, breed.Type, false); err != nil {
+			a.errorResponse(w, r, speciesErrorStatus(err), err)
+			return
+		}
+
+		result, err := a.breedRepo.Insert(ctx, breed)
+		if err != nil {
+			a.errorResponse(w, r, breedErrorStatus(err), err)
+			return
+		}
+
+		a.jsonResponse(w, r, http.StatusCreated, result)
+	case http.MethodOptions:
+		w.Header().Set("Allow", "GET, POST, OPTIONS")
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.Header().Set("Allow", "GET, POST, OPTIONS")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleBreed serves /api/breed/{id}. A breed animals still use can be
+// renamed but not moved to another type or deleted.
+func (a *api) handleBreed(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	n, err := strconv.Atoi(path.Base(r.URL.Path))
+	if err != nil {
+		a.errorResponse(w, r, http.StatusNotFound, domain.ErrBreedNotFound)
+		return
+	}
+	id := domain.AnimalBreed(n)
+
+	switch r.Method {
+	case http.MethodGet:
+		breed, err := a.breedRepo.Get(ctx, id)
+		if err != nil {
+			a.errorResponse(w, r, breedErrorStatus(err), err)
+			return
+		}
+
+		a.jsonResponse(w, r, http.StatusOK, breed)
+	case http.MethodPut:
+		existing, err := a.breedRepo.Get(ctx, id)
+		if err != nil {
+			a.errorResponse(w, r, breedErrorStatus(err), err)
+			return
+		}
+
+		var breed domain.Breed
+		if err := json.NewDecoder(r.Body).Decode(&breed); err != nil {
+			a.errorResponse(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		if err := breed.Validate(); err != nil {
+			a.errorResponse(w, r, breedErrorStatus(err), err)
+			return
+		}
+
+		if _, err := a.validateType(ctx, breed.Type, false); err != nil {
+			a.errorResponse(w, r, speciesErrorStatus(err), err)
+			return
+		}
+
+		if breed.Type != existing.Type {
+			if err := a.checkBreedUnused(ctx, id); err != nil {
+				a.errorResponse(w, r, breedErrorStatus(err), err)
+				return
+			}
+		}
+
+		if err := a.breedRepo.Update(ctx, id, breed); err != nil {
+			a.errorResponse(w, r, breedErrorStatus(err), err)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	case http.MethodDelete:
+		if err := a.checkBreedUnused(ctx, id); err != nil {
+			a.errorResponse(w, r, breedErrorStatus(err), err)
+			return
+		}
+
+		if err := a.breedRepo.Delete(ctx, id); err != nil {
+			a.errorResponse(w, r, breedErrorStatus(err), err)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	case http.MethodOptions:
+		w.Header().Set("Allow", "GET, PUT, DELETE, OPTIONS")
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.Header().Set("Allow", "GET, PUT, DELETE, OPTIONS")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (a *api) checkBreedUnused(ctx context.Context, id domain.AnimalBreed) error {
+	used, err := a.animalsExist(ctx, domain.AnimalQuery{Breeds: []domain.AnimalBreed{id}})
+	if err != nil {
+		return err
+	}
+
+	if used {
+		return domain.ErrBreedInUse
+	}
+
+	return nil
+}
+
+// validateBreed checks an animal's breed is in the catalog under the
+// animal's type. Animals without a breed are fine.
+func (a *api) validateBreed(ctx context.Context, animal *domain.Animal) error {
+	if animal.Breed == 0 {
+		return nil
+	}
+
+	breed, err := a.breedRepo.Get(ctx, animal.Breed)
+	if err != nil {
+		if errors.Is(err, domain.ErrBreedNotFound) {
+			return domain.ErrUnknownBreed
+		}
+		return err
+	}
+
+	if breed.Type != animal.Type {
+		return domain.ErrBreedWrongType
+	}
+
+	return nil
+}
+
+func breedErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrBreedNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrBreedExists),
+		errors.Is(err, domain.ErrBreedInUse):
+		return http.StatusConflict
+	case errors.Is(err, domain.ErrMissingBreed),
+		errors.Is(err, domain.ErrUnknownBreed),
+		errors.Is(err, domain.ErrBreedWrongType):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,190 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/dspeirs7/animals/internal/domain"
+	"github.com/dspeirs7/animals/internal/middleware"
+)
+
+const calendarHorizon = 5 * 365 * 24 * time.Hour
+
+var errCalendarToken = errors.New("invalid calendar token")
+
+func (a *api) getVaccinationCalendar(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		ctx, cancel := context.WithCancel(r.Context())
+		defer cancel()
+
+		if _, ok := middleware.SessionFromContext(ctx); !ok {
+			token := r.URL.Query().Get("token")
+			if token == "" {
+				a.errorResponse(w, r, http.StatusUnauthorized, errCalendarToken)
+				return
+			}
+
+			user, err := a.userRepo.GetByCalendarToken(ctx, domain.HashToken(token))
+			if errors.Is(err, domain.ErrUserNotFound) || (err == nil && user.Disabled) {
+				a.errorResponse(w, r, http.StatusUnauthorized, errCalendarToken)
+				return
+			} else if err != nil {
+				a.errorResponse(w, r, http.StatusInternalServerError, err)
+				return
+			}
+		}
+
+		types, err := parseInts(r.URL.Query(), "type")
+		if err != nil {
+			a.errorResponse(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		query := domain.VaccinationDueQuery{To: time.Now().Add(calendarHorizon)}
+		for _, t := range types {
+			query.Types = append(query.Types, domain.AnimalType(t))
+		}
+
+		due, err := a.animalRepo.VaccinationsDue(ctx, query)
+		if err != nil {
+			a.errorResponse(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+		w.Header().Set("Content-Disposition", `inline; filename="vaccinations.ics"`)
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(vaccinationCalendar(due, a.typeNames(ctx), publicURL(r), time.Now())))
+	case http.MethodOptions:
+		w.Header().Set("Allow", "GET, OPTIONS")
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.Header().Set("Allow", "GET, OPTIONS")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (a *api) calendarToken(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		ctx, cancel := context.WithCancel(r.Context())
+		defer cancel()
+
+		session, _ := middleware.SessionFromContext(ctx)
+
+		token, hash, err := domain.NewCalendarToken()
+		if err != nil {
+			a.errorResponse(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		if err := a.userRepo.SetCalendarToken(ctx, session.Username, hash); err != nil {
+			a.errorResponse(w, r, userErrorStatus(err), err)
+			return
+		}
+
+		url := fmt.Sprintf("%s/api/calendar/vaccinations.ics?token=%s", publicURL(r), token)
+		a.jsonResponse(w, r, http.StatusOK, map[string]string{"token": token, "url": url})
+	case http.MethodOptions:
+		w.Header().Set("Allow", "POST, OPTIONS")
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.Header().Set("Allow", "POST, OPTIONS")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// publicURL is the address users reach the app on, used for links that
+// leave the app such as calendar events.
+func publicURL(r *http.Request) string {
+	if url := os.Getenv("PUBLIC_URL"); url != "" {
+		return strings.TrimSuffix(url, "/")
+	}
+
+	scheme := "http"
+	if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+
+	return fmt.Sprintf("%s://%s", scheme, r.Host)
+}
+
+func vaccinationCalendar(due []*domain.VaccinationDue, typeNames map[domain.AnimalType]string, baseURL string, now time.Time) string {
+	var b strings.Builder
+
+	writeICalLine(&b, "BEGIN:VCALENDAR")
+	writeICalLine(&b, "VERSION:2.0")
+	writeICalLine(&b, "PRODID:-//dspeirs7//animals//EN")
+	writeICalLine(&b, "CALSCALE:GREGORIAN")
+	writeICalLine(&b, "METHOD:PUBLISH")
+	writeICalLine(&b, "X-WR-CALNAME:Vaccinations")
+
+	stamp := now.UTC().Format("20060102T150405Z")
+
+	for _, d := range due {
+		date := d.Vaccination.DateNeeded.Time().UTC()
+		link := fmt.Sprintf("%s/animal/%s", baseURL, d.AnimalId.Hex())
+		typeName := speciesName(typeNames, d.AnimalType)
+		summary := fmt.Sprintf("%s: %s (%s)", d.AnimalName, d.Vaccination.Name, typeName)
+
+		writeICalLine(&b, "BEGIN:VEVENT")
+		writeICalLine(&b, fmt.Sprintf("UID:%s-%s-%s@animals", d.AnimalId.Hex(), icalUIDPart(d.Vaccination.Name), date.Format("20060102")))
+		writeICalLine(&b, "DTSTAMP:"+stamp)
+		writeICalLine(&b, "DTSTART;VALUE=DATE:"+date.Format("20060102"))
+		writeICalLine(&b, "DTEND;VALUE=DATE:"+date.AddDate(0, 0, 1).Format("20060102"))
+		writeICalLine(&b, "SUMMARY:"+escapeICalText(summary))
+		writeICalLine(&b, "DESCRIPTION:"+escapeICalText(fmt.Sprintf("%s vaccination due for %s the %s.\n%s", d.Vaccination.Name, d.AnimalName, strings.ToLower(typeName), link)))
+		writeICalLine(&b, "URL:"+link)
+		writeICalLine(&b, "CATEGORIES:"+escapeICalText(typeName))
+		writeICalLine(&b, "TRANSP:TRANSPARENT")
+		writeICalLine(&b, "END:VEVENT")
+	}
+
+	writeICalLine(&b, "END:VCALENDAR")
+
+	return b.String()
+}
+
+// writeICalLine folds content lines longer than 75 octets as required by
+// RFC 5545, without splitting multi-byte characters. Continuation lines
+// count their leading space towards the limit.
+func writeICalLine(b *strings.Builder, line string) {
+	limit := 75
+
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		limit = 74
+	}
+
+	b.WriteString(line)
+	b.WriteString("\r\n")
+}
+
+var icalEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+func escapeICalText(s string) string {
+	return icalEscaper.Replace(s)
+}
+
+func icalUIDPart(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '-'
+	}, s)
+}
@@ -0,0 +1,108 @@
+package api
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/dspeirs7/animals/internal/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestWriteICalLine(t *testing.T) {
+	tests := []struct {
+		name  string
+		line  string
+		lines int
+	}{
+		{"short", "SUMMARY:Rabies", 1},
+		{"exactly 75 octets", "SUMMARY:" + strings.Repeat("a", 67), 1},
+		{"76 octets", "SUMMARY:" + strings.Repeat("a", 68), 2},
+		{"several folds", "DESCRIPTION:" + strings.Repeat("b", 200), 3},
+		{"multi-byte", "SUMMARY:" + strings.Repeat("é", 60), 2},
+		{"four byte runes", "SUMMARY:" + strings.Repeat("🐔", 40), 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b strings.Builder
+			writeICalLine(&b, tt.line)
+
+			out := b.String()
+			if !strings.HasSuffix(out, "\r\n") {
+				t.Fatalf("output %q does not end with CRLF", out)
+			}
+
+			physical := strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n")
+			if len(physical) != tt.lines {
+				t.Errorf("got %d lines, want %d", len(physical), tt.lines)
+			}
+
+			for i, l := range physical {
+				if len(l) > 75 {
+					t.Errorf("line %d is %d octets", i, len(l))
+				}
+				if !utf8.ValidString(l) {
+					t.Errorf("line %d splits a character: %q", i, l)
+				}
+				if i > 0 && !strings.HasPrefix(l, " ") {
+					t.Errorf("continuation line %d does not start with a space", i)
+				}
+			}
+
+			if unfolded := strings.ReplaceAll(strings.TrimSuffix(out, "\r\n"), "\r\n ", ""); unfolded != tt.line {
+				t.Errorf("unfolded to %q, want %q", unfolded, tt.line)
+			}
+		})
+	}
+}
+
+func TestEscapeICalText(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"plain", "plain"},
+		{"a;b,c", `a\;b\,c`},
+		{`back\slash`, `back\\slash`},
+		{"two\nlines", `two\nlines`},
+		{"crlf\r\nline", `crlf\nline`},
+	}
+
+	for _, tt := range tests {
+		if got := escapeICalText(tt.in); got != tt.want {
+			t.Errorf("escapeICalText(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestVaccinationCalendar(t *testing.T) {
+	id := primitive.NewObjectID()
+	due := []*domain.VaccinationDue{{
+		AnimalId:   id,
+		AnimalName: "Henrietta",
+		AnimalType: domain.ChickenType,
+		Vaccination: domain.Vaccination{
+			Name:       "Marek's",
+			DateNeeded: primitive.NewDateTimeFromTime(time.Date(2024, 3, 9, 0, 0, 0, 0, time.UTC)),
+		},
+	}}
+
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	out := vaccinationCalendar(due, map[domain.AnimalType]string{domain.ChickenType: "Hen"}, "https://farm.example", now)
+
+	for _, want := range []string{
+		"BEGIN:VCALENDAR\r\n",
+		"UID:" + id.Hex() + "-Marek-s-20240309@animals\r\n",
+		"DTSTAMP:20240301T120000Z\r\n",
+		"DTSTART;VALUE=DATE:20240309\r\n",
+		"DTEND;VALUE=DATE:20240310\r\n",
+		"SUMMARY:Henrietta: Marek's (Hen)\r\n",
+		"CATEGORIES:Hen\r\n",
+		"END:VCALENDAR\r\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("calendar is missing %q", want)
+		}
+	}
+}
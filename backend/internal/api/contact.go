@@ -0,0 +1,150 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"path"
+
+	"github.com/dspeirs7/animals/internal/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// getContacts lists adopters, fosters and buyers, optionally those whose
+// name starts with ?name=.
+func (a *api) getContacts(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	switch r.Method {
+	case http.MethodGet:
+		contacts, err := a.contactRepo.List(ctx, r.URL.Query().Get("name"))
+		if err != nil {
+			a.errorResponse(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		a.jsonResponse(w, r, http.StatusOK, contacts)
+	case http.MethodPost:
+		var contact domain.Contact
+		if err := json.NewDecoder(r.Body).Decode(&contact); err != nil {
+			a.errorResponse(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		if err := contact.Validate(); err != nil {
+			a.errorResponse(w, r, contactErrorStatus(err), err)
+			return
+		}
+
+		result, err := a.contactRepo.Insert(ctx, contact)
+		if err != nil {
+			a.errorResponse(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		a.jsonResponse(w, r, http.StatusCreated, result)
+	case http.MethodOptions:
+		w.Header().Set("Allow", "GET, POST, OPTIONS")
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.Header().Set("Allow", "GET, POST, OPTIONS")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleContact serves /api/contact/{id}. GET includes every animal the
+// contact has had; contacts in an animal's history can't be deleted.
+func (a *api) handleContact(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	id := path.Base(r.URL.Path)
+
+	switch r.Method {
+	case http.MethodGet:
+		contact, err := a.contactRepo.GetById(ctx, id)
+		if err != nil {
+			a.errorResponse(w, r, contactErrorStatus(err), err)
+			return
+		}
+
+		if contact.Animals, err = a.animalRepo.ContactAnimals(ctx, contact.Id); err != nil {
+			a.errorResponse(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		a.jsonResponse(w, r, http.StatusOK, contact)
+	case http.MethodPut:
+		var contact domain.Contact
+		if err := json.NewDecoder(r.Body).Decode(&contact); err != nil {
+			a.errorResponse(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		if err := contact.Validate(); err != nil {
+			a.errorResponse(w, r, contactErrorStatus(err), err)
+			return
+		}
+
+		if err := a.contactRepo.Update(ctx, id, contact); err != nil {
+			a.errorResponse(w, r, contactErrorStatus(err), err)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	case http.MethodDelete:
+		contact, err := a.contactRepo.GetById(ctx, id)
+		if err != nil {
+			a.errorResponse(w, r, contactErrorStatus(err), err)
+			return
+		}
+
+		animals, err := a.animalRepo.ContactAnimals(ctx, contact.Id)
+		if err != nil {
+			a.errorResponse(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		if len(animals) > 0 {
+			a.errorResponse(w, r, http.StatusConflict, domain.ErrContactInUse)
+			return
+		}
+
+		if err := a.contactRepo.Delete(ctx, id); err != nil {
+			a.errorResponse(w, r, contactErrorStatus(err), err)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	case http.MethodOptions:
+		w.Header().Set("Allow", "GET, PUT, DELETE, OPTIONS")
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.Header().Set("Allow", "GET, PUT, DELETE, OPTIONS")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// checkContact makes sure a contact an animal is handed to exists.
+func (a *api) checkContact(ctx context.Context, contactId *primitive.ObjectID) error {
+	if contactId == nil {
+		return nil
+	}
+
+	_, err := a.contactRepo.GetById(ctx, contactId.Hex())
+	return err
+}
+
+func contactErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrContactNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrContactInUse):
+		return http.StatusConflict
+	case errors.Is(err, domain.ErrMissingContact):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
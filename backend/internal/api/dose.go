@@ -0,0 +1,205 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/dspeirs7/animals/internal/domain"
+	"github.com/dspeirs7/animals/internal/middleware"
+)
+
+const defaultMissedWithin = 7 * 24 * time.Hour
+
+type administerRequest struct {
+	AdministeredAt *time.Time `json:"administeredAt"`
+	Note           string     `json:"note"`
+}
+
+// getDosesToday lists every dose due today in the farm's time zone, given
+// or not. ?type= limits it to some animal types.
+func (a *api) getDosesToday(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		now := time.Now()
+		from := domain.StartOfDay(now, a.location)
+		to := from.AddDate(0, 0, 1)
+
+		a.doses(w, r, from, to, now, false)
+	case http.MethodOptions:
+		w.Header().Set("Allow", "GET, OPTIONS")
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.Header().Set("Allow", "GET, OPTIONS")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// getDosesMissed lists the doses missed in the last ?within= (7d by
+// default).
+func (a *api) getDosesMissed(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		within := defaultMissedWithin
+		if v := r.URL.Query().Get("within"); v != "" {
+			var err error
+			if within, err = parseWithin(v); err != nil {
+				a.errorResponse(w, r, http.StatusBadRequest, err)
+				return
+			}
+		}
+
+		now := time.Now()
+		a.doses(w, r, now.Add(-within), now, now, true)
+	case http.MethodOptions:
+		w.Header().Set("Allow", "GET, OPTIONS")
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.Header().Set("Allow", "GET, OPTIONS")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (a *api) doses(w http.ResponseWriter, r *http.Request, from, to, now time.Time, missedOnly bool) {
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	types, err := parseInts(r.URL.Query(), "type")
+	if err != nil {
+		a.errorResponse(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	var animalTypes []domain.AnimalType
+	for _, t := range types {
+		animalTypes = append(animalTypes, domain.AnimalType(t))
+	}
+
+	medications, err := a.medicalRepo.ActiveMedications(ctx, from, to, animalTypes)
+	if err != nil {
+		a.errorResponse(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	given, err := a.doseRepo.Between(ctx, from, to)
+	if err != nil {
+		a.errorResponse(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	doses := domain.ScheduleDoses(medications, given, from, to, now, a.location)
+
+	if missedOnly {
+		missed := []*domain.Dose{}
+		for _, dose := range doses {
+			if dose.Missed {
+				missed = append(missed, dose)
+			}
+		}
+		doses = missed
+	}
+
+	a.jsonResponse(w, r, http.StatusOK, doses)
+}
+
+// administerDose serves POST /api/doses/{id}/administer, recording who
+// gave the dose and when. The body is optional.
+func (a *api) administerDose(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(parts) != 4 || parts[3] != "administer" {
+		http.NotFound(w, r)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPost:
+		ctx, cancel := context.WithCancel(r.Context())
+		defer cancel()
+
+		var request administerRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil && !errors.Is(err, io.EOF) {
+			a.errorResponse(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		dose, err := a.scheduledDose(ctx, parts[2])
+		if err != nil {
+			a.errorResponse(w, r, doseErrorStatus(err), err)
+			return
+		}
+
+		administeredAt := time.Now()
+		if request.AdministeredAt != nil {
+			administeredAt = *request.AdministeredAt
+		}
+		dose.AdministeredAt = &administeredAt
+		dose.Note = request.Note
+
+		if session, ok := middleware.SessionFromContext(ctx); ok {
+			dose.AdministeredBy = session.Username
+		}
+
+		if err := a.doseRepo.Administer(ctx, *dose); err != nil {
+			a.errorResponse(w, r, doseErrorStatus(err), err)
+			return
+		}
+
+		a.jsonResponse(w, r, http.StatusOK, dose)
+	case http.MethodOptions:
+		w.Header().Set("Allow", "POST, OPTIONS")
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.Header().Set("Allow", "POST, OPTIONS")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// scheduledDose looks up the dose an Id refers to, making sure its course
+// really has a dose at that time.
+func (a *api) scheduledDose(ctx context.Context, id string) (*domain.Dose, error) {
+	medicationId, due, err := domain.ParseDoseId(id)
+	if err != nil {
+		return nil, err
+	}
+
+	course, err := a.medicalRepo.GetById(ctx, medicationId.Hex())
+	if err != nil {
+		if errors.Is(err, domain.ErrMedicalRecordNotFound) {
+			return nil, domain.ErrDoseNotFound
+		}
+		return nil, err
+	}
+
+	if len(course.DoseTimes(due, due.Add(time.Minute), a.location)) == 0 {
+		return nil, domain.ErrDoseNotFound
+	}
+
+	animal, err := a.animalRepo.GetById(ctx, course.AnimalId.Hex())
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.Dose{
+		Id:           domain.DoseId(course.Id, due),
+		MedicationId: course.Id,
+		AnimalId:     course.AnimalId,
+		AnimalName:   animal.Name,
+		Drug:         course.Drug,
+		Dose:         course.Dose,
+		Due:          due,
+	}, nil
+}
+
+func doseErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrDoseNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrDoseAdministered):
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,275 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/dspeirs7/animals/internal/domain"
+	"github.com/dspeirs7/animals/internal/middleware"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// getEggLogs lists egg logs, newest first, and records a day's counts.
+// Recording the same hen or coop on the same day again replaces the count.
+func (a *api) getEggLogs(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	switch r.Method {
+	case http.MethodGet:
+		values := r.URL.Query()
+
+		var query domain.EggLogQuery
+
+		from, to, err := parseDateRange(values.Get("from"), values.Get("to"))
+		if err != nil {
+			a.errorResponse(w, r, http.StatusBadRequest, err)
+			return
+		}
+		query.From, query.To = from, to
+
+		if v := values.Get("animal"); v != "" {
+			id, err := primitive.ObjectIDFromHex(v)
+			if err != nil {
+				a.errorResponse(w, r, http.StatusBadRequest, err)
+				return
+			}
+			query.AnimalId = &id
+		}
+		query.Coop = values.Get("coop")
+
+		logs, err := a.eggRepo.Find(ctx, query)
+		if err != nil {
+			a.errorResponse(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		a.jsonResponse(w, r, http.StatusOK, logs)
+	case http.MethodPost:
+		var logs []domain.EggLog
+		if err := json.NewDecoder(r.Body).Decode(&logs); err != nil {
+			a.errorResponse(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		var recordedBy string
+		if session, ok := middleware.SessionFromContext(ctx); ok {
+			recordedBy = session.Username
+		}
+
+		for i := range logs {
+			if err := a.validateEggLog(ctx, &logs[i]); err != nil {
+				a.errorResponse(w, r, http.StatusBadRequest, err)
+				return
+			}
+
+			logs[i].RecordedBy = recordedBy
+		}
+
+		results := make([]*domain.EggLog, 0, len(logs))
+
+		for _, log := range logs {
+			result, err := a.eggRepo.Record(ctx, log)
+			if err != nil {
+				a.errorResponse(w, r, http.StatusInternalServerError, err)
+				return
+			}
+			results = append(results, result)
+		}
+
+		a.jsonResponse(w, r, http.StatusOK, results)
+	case http.MethodOptions:
+		w.Header().Set("Allow", "GET, POST, OPTIONS")
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.Header().Set("Allow", "GET, POST, OPTIONS")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (a *api) handleEggLog(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	id := path.Base(r.URL.Path)
+
+	switch r.Method {
+	case http.MethodDelete:
+		if err := a.eggRepo.Delete(ctx, id); err != nil {
+			a.errorResponse(w, r, eggErrorStatus(err), err)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	case http.MethodOptions:
+		w.Header().Set("Allow", "DELETE, OPTIONS")
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.Header().Set("Allow", "DELETE, OPTIONS")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// getEggStats serves /api/eggs/stats?interval=week&groupBy=breed&window=4.
+func (a *api) getEggStats(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	switch r.Method {
+	case http.MethodGet:
+		values := r.URL.Query()
+
+		query := domain.EggStatsQuery{
+			Interval: domain.EggDaily,
+			Group:    domain.EggGroup(values.Get("groupBy")),
+			Window:   domain.DefaultEggWindow,
+		}
+
+		if v := values.Get("interval"); v != "" {
+			query.Interval = domain.EggInterval(v)
+		}
+		if !query.Interval.Valid() {
+			a.errorResponse(w, r, http.StatusBadRequest, domain.ErrInvalidInterval)
+			return
+		}
+
+		if !query.Group.Valid() {
+			a.errorResponse(w, r, http.StatusBadRequest, domain.ErrInvalidEggGroup)
+			return
+		}
+
+		if v := values.Get("window"); v != "" {
+			window, err := strconv.Atoi(v)
+			if err != nil || window < 1 || window > 366 {
+				a.errorResponse(w, r, http.StatusBadRequest, domain.ErrInvalidEggWindow)
+				return
+			}
+			query.Window = window
+		}
+
+		from, to, err := parseDateRange(values.Get("from"), values.Get("to"))
+		if err != nil {
+			a.errorResponse(w, r, http.StatusBadRequest, err)
+			return
+		}
+		query.From, query.To = from, to
+
+		stats, err := a.eggRepo.Stats(ctx, query)
+		if err != nil {
+			a.errorResponse(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		a.jsonResponse(w, r, http.StatusOK, stats)
+	case http.MethodOptions:
+		w.Header().Set("Allow", "GET, OPTIONS")
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.Header().Set("Allow", "GET, OPTIONS")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// getEggStreaks serves /api/eggs/streaks, optionally limited to ?from=.
+func (a *api) getEggStreaks(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	switch r.Method {
+	case http.MethodGet:
+		from, _, err := parseDateRange(r.URL.Query().Get("from"), "")
+		if err != nil {
+			a.errorResponse(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		streaks, err := a.eggRepo.Streaks(ctx, from)
+		if err != nil {
+			a.errorResponse(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		today := domain.EggDay(time.Now(), a.location)
+		for _, streak := range streaks {
+			streak.SetCurrent(today)
+		}
+
+		a.jsonResponse(w, r, http.StatusOK, streaks)
+	case http.MethodOptions:
+		w.Header().Set("Allow", "GET, OPTIONS")
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.Header().Set("Allow", "GET, OPTIONS")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// validateEggLog checks the log, moves its date onto the calendar day and
+// makes sure a per-hen log is for an animal whose type lays eggs.
+func (a *api) validateEggLog(ctx context.Context, log *domain.EggLog) error {
+	if err := log.Validate(); err != nil {
+		return err
+	}
+
+	date := time.Now()
+	if log.Date != 0 {
+		date = log.Date.Time()
+	}
+	log.Date = primitive.NewDateTimeFromTime(domain.EggDay(date, a.location))
+
+	if log.AnimalId == nil {
+		return nil
+	}
+
+	animal, err := a.animalRepo.GetById(ctx, log.AnimalId.Hex())
+	if err != nil {
+		return err
+	}
+
+	species, err := a.speciesRepo.GetById(ctx, animal.Type)
+	if err != nil && !errors.Is(err, domain.ErrSpeciesNotFound) {
+		return err
+	}
+
+	if species == nil || !species.LaysEggs {
+		return domain.ErrNotALayer
+	}
+
+	return nil
+}
+
+// parseDateRange parses optional from/to days; to is inclusive, so the
+// range returned ends at the start of the following day.
+func parseDateRange(from, to string) (time.Time, time.Time, error) {
+	var start, end time.Time
+
+	if from != "" {
+		t, err := parseDate(from)
+		if err != nil {
+			return start, end, err
+		}
+		start = domain.EggDay(t, time.UTC)
+	}
+
+	if to != "" {
+		t, err := parseDate(to)
+		if err != nil {
+			return start, end, err
+		}
+		end = domain.EggDay(t, time.UTC).AddDate(0, 0, 1)
+	}
+
+	return start, end, nil
+}
+
+func eggErrorStatus(err error) int {
+	if errors.Is(err, domain.ErrEggLogNotFound) {
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,444 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dspeirs7/animals/internal/domain"
+	"github.com/dspeirs7/animals/internal/middleware"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var errUnknownAnimal = errors.New("unknown animal")
+
+type fedRequest struct {
+	FedAt *time.Time `json:"fedAt"`
+	Note  string     `json:"note"`
+}
+
+func (a *api) getFeedingPlans(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	switch r.Method {
+	case http.MethodGet:
+		var activeOnly bool
+		if v := r.URL.Query().Get("active"); v != "" {
+			var err error
+			if activeOnly, err = strconv.ParseBool(v); err != nil {
+				a.errorResponse(w, r, http.StatusBadRequest, err)
+				return
+			}
+		}
+
+		plans, err := a.feedingRepo.Plans(ctx, activeOnly)
+		if err != nil {
+			a.errorResponse(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		a.jsonResponse(w, r, http.StatusOK, plans)
+	case http.MethodPost:
+		var plan domain.FeedingPlan
+		if err := json.NewDecoder(r.Body).Decode(&plan); err != nil {
+			a.errorResponse(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		if err := a.validateFeedingPlan(ctx, &plan); err != nil {
+			a.errorResponse(w, r, feedingErrorStatus(err), err)
+			return
+		}
+
+		result, err := a.feedingRepo.InsertPlan(ctx, plan)
+		if err != nil {
+			a.errorResponse(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		a.jsonResponse(w, r, http.StatusCreated, result)
+	case http.MethodOptions:
+		w.Header().Set("Allow", "GET, POST, OPTIONS")
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.Header().Set("Allow", "GET, POST, OPTIONS")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (a *api) handleFeedingPlan(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	id := path.Base(r.URL.Path)
+
+	switch r.Method {
+	case http.MethodGet:
+		plan, err := a.feedingRepo.GetPlan(ctx, id)
+		if err != nil {
+			a.errorResponse(w, r, feedingErrorStatus(err), err)
+			return
+		}
+
+		a.jsonResponse(w, r, http.StatusOK, plan)
+	case http.MethodPut:
+		var plan domain.FeedingPlan
+		if err := json.NewDecoder(r.Body).Decode(&plan); err != nil {
+			a.errorResponse(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		if err := a.validateFeedingPlan(ctx, &plan); err != nil {
+			a.errorResponse(w, r, feedingErrorStatus(err), err)
+			return
+		}
+
+		if err := a.feedingRepo.UpdatePlan(ctx, id, plan); err != nil {
+			a.errorResponse(w, r, feedingErrorStatus(err), err)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	case http.MethodDelete:
+		if err := a.feedingRepo.DeletePlan(ctx, id); err != nil {
+			a.errorResponse(w, r, feedingErrorStatus(err), err)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	case http.MethodOptions:
+		w.Header().Set("Allow", "GET, PUT, DELETE, OPTIONS")
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.Header().Set("Allow", "GET, PUT, DELETE, OPTIONS")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// animalFeedingPlans serves /api/animal/{id}/feeding: the plans covering
+// the animal, on its own or through its type.
+func (a *api) animalFeedingPlans(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		ctx, cancel := context.WithCancel(r.Context())
+		defer cancel()
+
+		animal := r.Context().Value("animal").(*domain.Animal)
+
+		plans, err := a.feedingRepo.PlansFor(ctx, animal)
+		if err != nil {
+			a.errorResponse(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		a.jsonResponse(w, r, http.StatusOK, plans)
+	case http.MethodOptions:
+		w.Header().Set("Allow", "GET, OPTIONS")
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.Header().Set("Allow", "GET, OPTIONS")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// getFeedings is the feeding log: the feedings given between ?from= and
+// ?to=, newest first.
+func (a *api) getFeedings(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		ctx, cancel := context.WithCancel(r.Context())
+		defer cancel()
+
+		values := r.URL.Query()
+
+		var from, to time.Time
+		if v := values.Get("from"); v != "" {
+			t, err := parseDate(v)
+			if err != nil {
+				a.errorResponse(w, r, http.StatusBadRequest, err)
+				return
+			}
+			from = domain.StartOfDay(t, a.location)
+		}
+		if v := values.Get("to"); v != "" {
+			t, err := parseDate(v)
+			if err != nil {
+				a.errorResponse(w, r, http.StatusBadRequest, err)
+				return
+			}
+			to = domain.StartOfDay(t, a.location).AddDate(0, 0, 1)
+		}
+
+		feedings, err := a.feedingRepo.Feedings(ctx, from, to)
+		if err != nil {
+			a.errorResponse(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		a.jsonResponse(w, r, http.StatusOK, feedings)
+	case http.MethodOptions:
+		w.Header().Set("Allow", "GET, OPTIONS")
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.Header().Set("Allow", "GET, OPTIONS")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// getFeedingsToday lists every feeding scheduled today, given or not.
+func (a *api) getFeedingsToday(w http.ResponseWriter, r *http.Request) {
+	a.feedingsToday(w, r, false)
+}
+
+// getFeedingsOutstanding lists today's feedings nobody has given yet.
+func (a *api) getFeedingsOutstanding(w http.ResponseWriter, r *http.Request) {
+	a.feedingsToday(w, r, true)
+}
+
+func (a *api) feedingsToday(w http.ResponseWriter, r *http.Request, outstandingOnly bool) {
+	switch r.Method {
+	case http.MethodGet:
+		ctx, cancel := context.WithCancel(r.Context())
+		defer cancel()
+
+		from := domain.StartOfDay(time.Now(), a.location)
+		to := from.AddDate(0, 0, 1)
+
+		plans, err := a.feedingRepo.Plans(ctx, true)
+		if err != nil {
+			a.errorResponse(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		plans, err = a.feedablePlans(ctx, plans)
+		if err != nil {
+			a.errorResponse(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		given, err := a.feedingRepo.Feedings(ctx, from, to)
+		if err != nil {
+			a.errorResponse(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		feedings := domain.ScheduleFeedings(plans, given, from, to, a.location)
+
+		if outstandingOnly {
+			outstanding := []*domain.Feeding{}
+			for _, feeding := range feedings {
+				if feeding.FedAt == nil {
+					outstanding = append(outstanding, feeding)
+				}
+			}
+			feedings = outstanding
+		}
+
+		a.jsonResponse(w, r, http.StatusOK, feedings)
+	case http.MethodOptions:
+		w.Header().Set("Allow", "GET, OPTIONS")
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.Header().Set("Allow", "GET, OPTIONS")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// feed serves POST /api/feedings/{id}/fed, recording who gave a scheduled
+// feeding and when. The body is optional.
+func (a *api) feed(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(parts) != 4 || parts[3] != "fed" {
+		http.NotFound(w, r)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPost:
+		ctx, cancel := context.WithCancel(r.Context())
+		defer cancel()
+
+		var request fedRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil && !errors.Is(err, io.EOF) {
+			a.errorResponse(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		planId, due, err := domain.ParseFeedingId(parts[2])
+		if err != nil {
+			a.errorResponse(w, r, feedingErrorStatus(err), err)
+			return
+		}
+
+		plan, err := a.feedingRepo.GetPlan(ctx, planId.Hex())
+		if err != nil {
+			if errors.Is(err, domain.ErrFeedingPlanNotFound) {
+				err = domain.ErrFeedingNotFound
+			}
+			a.errorResponse(w, r, feedingErrorStatus(err), err)
+			return
+		}
+
+		if plan.Paused {
+			a.errorResponse(w, r, http.StatusConflict, domain.ErrPlanPaused)
+			return
+		}
+
+		feedable, err := a.feedablePlans(ctx, []*domain.FeedingPlan{plan})
+		if err != nil {
+			a.errorResponse(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		if len(feedable) == 0 {
+			a.errorResponse(w, r, http.StatusNotFound, domain.ErrFeedingNotFound)
+			return
+		}
+		plan = feedable[0]
+
+		if len(plan.FeedingTimes(due, due.Add(time.Minute), a.location)) == 0 {
+			a.errorResponse(w, r, http.StatusNotFound, domain.ErrFeedingNotFound)
+			return
+		}
+
+		feeding := domain.NewFeeding(plan, due)
+
+		fedAt := time.Now()
+		if request.FedAt != nil {
+			fedAt = *request.FedAt
+		}
+		feeding.FedAt = &fedAt
+		feeding.Note = request.Note
+
+		if session, ok := middleware.SessionFromContext(ctx); ok {
+			feeding.FedBy = session.Username
+		}
+
+		if err := a.feedingRepo.Feed(ctx, *feeding); err != nil {
+			a.errorResponse(w, r, feedingErrorStatus(err), err)
+			return
+		}
+
+		a.jsonResponse(w, r, http.StatusOK, feeding)
+	case http.MethodOptions:
+		w.Header().Set("Allow", "POST, OPTIONS")
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.Header().Set("Allow", "POST, OPTIONS")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// validateFeedingPlan checks the plan and that the animals it lists exist.
+func (a *api) validateFeedingPlan(ctx context.Context, plan *domain.FeedingPlan) error {
+	if err := plan.Validate(); err != nil {
+		return err
+	}
+
+	for _, id := range plan.AnimalIds {
+		animal, err := a.animalRepo.GetById(ctx, id.Hex())
+		if err != nil {
+			return err
+		}
+
+		if animal.Id.IsZero() {
+			return fmt.Errorf("%w: %s", errUnknownAnimal, id.Hex())
+		}
+	}
+
+	return nil
+}
+
+// feedablePlans narrows each plan to the animals still in our care and out
+// of the trash, leaving out plans with nobody left to feed. The stored plans
+// keep trashed animals, so restoring one puts it back on its plan.
+func (a *api) feedablePlans(ctx context.Context, plans []*domain.FeedingPlan) ([]*domain.FeedingPlan, error) {
+	var ids []primitive.ObjectID
+	for _, plan := range plans {
+		ids = append(ids, plan.AnimalIds...)
+	}
+
+	active := make(map[primitive.ObjectID]bool, len(ids))
+	if len(ids) > 0 {
+		query := domain.AnimalQuery{Ids: ids, Statuses: domain.ActiveStatuses, Limit: domain.MaxAnimalLimit}
+
+		for {
+			page, err := a.animalRepo.Find(ctx, query)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, animal := range page.Animals {
+				active[animal.Id] = true
+			}
+
+			if page.NextCursor == "" {
+				break
+			}
+			query.Cursor = page.NextCursor
+		}
+	}
+
+	typeActive := make(map[domain.AnimalType]bool)
+	feedable := make([]*domain.FeedingPlan, 0, len(plans))
+
+	for _, plan := range plans {
+		if plan.Type != 0 {
+			found, ok := typeActive[plan.Type]
+			if !ok {
+				page, err := a.animalRepo.Find(ctx, domain.AnimalQuery{Types: []domain.AnimalType{plan.Type}, Statuses: domain.ActiveStatuses, Limit: 1})
+				if err != nil {
+					return nil, err
+				}
+				found = page.Total > 0
+				typeActive[plan.Type] = found
+			}
+
+			if found {
+				feedable = append(feedable, plan)
+			}
+			continue
+		}
+
+		narrowed := *plan
+		narrowed.AnimalIds = nil
+		for _, id := range plan.AnimalIds {
+			if active[id] {
+				narrowed.AnimalIds = append(narrowed.AnimalIds, id)
+			}
+		}
+
+		if len(narrowed.AnimalIds) > 0 {
+			feedable = append(feedable, &narrowed)
+		}
+	}
+
+	return feedable, nil
+}
+
+func feedingErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrFeedingPlanNotFound),
+		errors.Is(err, domain.ErrFeedingNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrAlreadyFed),
+		errors.Is(err, domain.ErrPlanPaused):
+		return http.StatusConflict
+	case errors.Is(err, errUnknownAnimal),
+		errors.Is(err, domain.ErrFeedingTarget),
+		errors.Is(err, domain.ErrMissingFood),
+		errors.Is(err, domain.ErrInvalidAmount),
+		errors.Is(err, domain.ErrMissingFeedingTimes),
+		errors.Is(err, domain.ErrInvalidTimeOfDay):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,232 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"strconv"
+
+	"github.com/dspeirs7/animals/internal/domain"
+	"go.uber.org/zap"
+)
+
+func (a *api) getFields(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	switch r.Method {
+	case http.MethodGet:
+		var animalType domain.AnimalType
+		if v := r.URL.Query().Get("type"); v != "" {
+			t, err := strconv.Atoi(v)
+			if err != nil {
+				a.errorResponse(w, r, http.StatusBadRequest, err)
+				return
+			}
+			animalType = domain.AnimalType(t)
+		}
+
+		fields, err := a.fieldRepo.List(ctx, animalType)
+		if err != nil {
+			a.errorResponse(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		a.jsonResponse(w, r, http.StatusOK, fields)
+	case http.MethodPost:
+		var field domain.FieldDefinition
+		if err := json.NewDecoder(r.Body).Decode(&field); err != nil {
+			a.errorResponse(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		if err := field.Validate(); err != nil {
+			a.errorResponse(w, r, fieldErrorStatus(err), err)
+			return
+		}
+
+		if _, err := a.validateType(ctx, field.Type, false); err != nil {
+			a.errorResponse(w, r, speciesErrorStatus(err), err)
+			return
+		}
+
+		if field.Required {
+			if err := a.checkFieldFilled(ctx, &field); err != nil {
+				a.errorResponse(w, r, fieldErrorStatus(err), err)
+				return
+			}
+		}
+
+		result, err := a.fieldRepo.Insert(ctx, field)
+		if err != nil {
+			a.errorResponse(w, r, fieldErrorStatus(err), err)
+			return
+		}
+
+		a.jsonResponse(w, r, http.StatusCreated, result)
+	case http.MethodOptions:
+		w.Header().Set("Allow", "GET, POST, OPTIONS")
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.Header().Set("Allow", "GET, POST, OPTIONS")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleField serves /api/field/{id}. A field's type, key and kind are fixed
+// once animals may carry values for it, and it only becomes required once
+// every animal of its type has a value. Deleting the field drops the values.
+func (a *api) handleField(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	id := path.Base(r.URL.Path)
+
+	switch r.Method {
+	case http.MethodGet:
+		field, err := a.fieldRepo.GetById(ctx, id)
+		if err != nil {
+			a.errorResponse(w, r, fieldErrorStatus(err), err)
+			return
+		}
+
+		a.jsonResponse(w, r, http.StatusOK, field)
+	case http.MethodPut:
+		existing, err := a.fieldRepo.GetById(ctx, id)
+		if err != nil {
+			a.errorResponse(w, r, fieldErrorStatus(err), err)
+			return
+		}
+
+		var field domain.FieldDefinition
+		if err := json.NewDecoder(r.Body).Decode(&field); err != nil {
+			a.errorResponse(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		field.Type, field.Key = existing.Type, existing.Key
+
+		if err := field.Validate(); err != nil {
+			a.errorResponse(w, r, fieldErrorStatus(err), err)
+			return
+		}
+
+		if field.Kind != existing.Kind {
+			a.errorResponse(w, r, fieldErrorStatus(domain.ErrFieldKindChange), domain.ErrFieldKindChange)
+			return
+		}
+
+		if field.Required && !existing.Required {
+			if err := a.checkFieldFilled(ctx, &field); err != nil {
+				a.errorResponse(w, r, fieldErrorStatus(err), err)
+				return
+			}
+		}
+
+		if err := a.fieldRepo.Update(ctx, id, field); err != nil {
+			a.errorResponse(w, r, fieldErrorStatus(err), err)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	case http.MethodDelete:
+		field, err := a.fieldRepo.GetById(ctx, id)
+		if err != nil {
+			a.errorResponse(w, r, fieldErrorStatus(err), err)
+			return
+		}
+
+		if err := a.fieldRepo.Delete(ctx, id); err != nil {
+			a.errorResponse(w, r, fieldErrorStatus(err), err)
+			return
+		}
+
+		if err := a.animalRepo.RemoveAttribute(ctx, field.Type, field.Key); err != nil {
+			a.logger.Warn("error removing attribute", zap.String("field", id), zap.Error(err))
+		}
+
+		w.WriteHeader(http.StatusOK)
+	case http.MethodOptions:
+		w.Header().Set("Allow", "GET, PUT, DELETE, OPTIONS")
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.Header().Set("Allow", "GET, PUT, DELETE, OPTIONS")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// validateAttributes checks an animal's attributes against the fields of
+// its type, normalising them for storage.
+func (a *api) validateAttributes(ctx context.Context, animal *domain.Animal) error {
+	fields, err := a.fieldRepo.List(ctx, animal.Type)
+	if err != nil {
+		return err
+	}
+
+	animal.Attributes, err = domain.ValidateAttributes(fields, animal.Attributes)
+	return err
+}
+
+// resolveAttributeFilters checks a listing's attribute filters against the
+// fields of the types it covers.
+func (a *api) resolveAttributeFilters(ctx context.Context, query *domain.AnimalQuery) error {
+	fields, err := a.fieldRepo.List(ctx, 0)
+	if err != nil {
+		return err
+	}
+
+	if len(query.Types) > 0 {
+		types := make(map[domain.AnimalType]bool, len(query.Types))
+		for _, t := range query.Types {
+			types[t] = true
+		}
+
+		covered := fields[:0]
+		for _, field := range fields {
+			if types[field.Type] {
+				covered = append(covered, field)
+			}
+		}
+		fields = covered
+	}
+
+	return domain.ResolveAttributeFilters(fields, query.Attributes)
+}
+
+// checkFieldFilled makes sure every animal of the field's type has a value
+// for it, so requiring the field doesn't break their next update.
+func (a *api) checkFieldFilled(ctx context.Context, field *domain.FieldDefinition) error {
+	missing, err := a.animalRepo.CountMissingAttribute(ctx, field.Type, field.Key)
+	if err != nil {
+		return err
+	}
+
+	if missing > 0 {
+		return fmt.Errorf("%w: %d animals", domain.ErrFieldUnfilled, missing)
+	}
+
+	return nil
+}
+
+func fieldErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrFieldNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrFieldExists),
+		errors.Is(err, domain.ErrFieldKindChange),
+		errors.Is(err, domain.ErrFieldUnfilled):
+		return http.StatusConflict
+	case errors.Is(err, domain.ErrMissingFieldType),
+		errors.Is(err, domain.ErrInvalidFieldKey),
+		errors.Is(err, domain.ErrInvalidFieldKind),
+		errors.Is(err, domain.ErrMissingOptions),
+		errors.Is(err, domain.ErrUnknownAttribute),
+		errors.Is(err, domain.ErrMissingAttribute),
+		errors.Is(err, domain.ErrInvalidAttribute):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
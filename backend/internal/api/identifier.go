@@ -0,0 +1,53 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/dspeirs7/animals/internal/domain"
+)
+
+// lookupIdentifier serves GET /api/lookup?chip=, finding the animal a
+// scanned microchip, leg band or collar tag belongs to.
+func (a *api) lookupIdentifier(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		ctx, cancel := context.WithCancel(r.Context())
+		defer cancel()
+
+		chip := strings.TrimSpace(r.URL.Query().Get("chip"))
+		if chip == "" {
+			a.errorResponse(w, r, http.StatusBadRequest, domain.ErrMissingLookup)
+			return
+		}
+
+		animal, err := a.animalRepo.FindByIdentifier(ctx, chip)
+		if err != nil {
+			a.errorResponse(w, r, identifierErrorStatus(err), err)
+			return
+		}
+
+		a.jsonResponse(w, r, http.StatusOK, animal)
+	case http.MethodOptions:
+		w.Header().Set("Allow", "GET, OPTIONS")
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.Header().Set("Allow", "GET, OPTIONS")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func identifierErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrNoIdentifier):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrIdentifierInUse):
+		return http.StatusConflict
+	case errors.Is(err, domain.ErrInvalidMicrochip),
+		errors.Is(err, domain.ErrMissingLookup):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
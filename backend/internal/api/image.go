@@ -0,0 +1,321 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/dspeirs7/animals/internal/domain"
+	"github.com/dspeirs7/animals/internal/imaging"
+	"github.com/dspeirs7/animals/internal/storage"
+	"go.uber.org/zap"
+)
+
+// uploadImage adds a photo to the animal's gallery and makes it the
+// primary photo.
+func (a *api) uploadImage(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	animal := r.Context().Value("animal").(*domain.Animal)
+
+	photo, status, err := a.addPhoto(ctx, w, r, animal, true)
+	if err != nil {
+		a.errorResponse(w, r, status, err)
+		return
+	}
+
+	a.jsonResponse(w, r, http.StatusOK, domain.Animal{ImageUrl: photo.Url, Images: photo.Images})
+}
+
+const (
+	defaultMaxUploadBytes = 10 << 20
+
+	// imageReuseWindow is how long after an image was stored deleteImageUrls
+	// leaves it alone, covering a concurrent upload of the same content.
+	imageReuseWindow = time.Minute
+)
+
+func uploadErrorStatus(err error) int {
+	var tooLarge *http.MaxBytesError
+
+	switch {
+	case errors.As(err, &tooLarge), errors.Is(err, imaging.ErrTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, imaging.ErrUnsupportedFormat):
+		return http.StatusUnsupportedMediaType
+	case errors.Is(err, http.ErrMissingFile), errors.Is(err, http.ErrNotMultipart):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+// storeImage generates every variant of an upload and saves them under the
+// upload's SHA-256, so uploading the same photo twice reuses the stored
+// files.
+func (a *api) storeImage(ctx context.Context, r io.Reader) (*domain.ImageSet, error) {
+	raw, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	variants, err := imaging.Process(bytes.NewReader(raw))
+	if err != nil {
+		return nil, err
+	}
+
+	sum := sha256.Sum256(raw)
+	base := hex.EncodeToString(sum[:])
+	images := &domain.ImageSet{}
+	var created []string
+
+	for _, v := range variants {
+		key := fmt.Sprintf("%s-%s%s", base, v.Variant.Name, v.Ext)
+		url := a.imageStore.URL(key)
+
+		_, statErr := a.imageStore.Stat(ctx, key)
+		if statErr != nil && !errors.Is(statErr, domain.ErrImageNotFound) {
+			a.deleteImageUrls(ctx, created)
+			return nil, statErr
+		}
+
+		// A reused image is put again to refresh its modification time,
+		// which keeps the cleanup job and deleteImageUrls off it while the
+		// new photo that references it is saved.
+		if err := a.imageStore.Put(ctx, key, bytes.NewReader(v.Data), int64(len(v.Data)), v.ContentType); err != nil {
+			a.deleteImageUrls(ctx, created)
+			return nil, err
+		}
+
+		if errors.Is(statErr, domain.ErrImageNotFound) {
+			created = append(created, url)
+		}
+
+		switch v.Variant {
+		case imaging.Thumbnail:
+			images.Thumbnail = url
+		case imaging.Card:
+			images.Card = url
+		case imaging.Full:
+			images.Full = url
+		}
+	}
+
+	return images, nil
+}
+
+func (a *api) deleteAnimalImages(ctx context.Context, animal *domain.Animal) {
+	urls := []string{}
+	if animal.ImageUrl != "" {
+		urls = append(urls, animal.ImageUrl)
+	}
+	if animal.Images != nil {
+		urls = append(urls, animal.Images.URLs()...)
+	}
+	for _, photo := range animal.Photos {
+		urls = append(urls, photo.URLs()...)
+	}
+
+	a.deleteImageUrls(ctx, urls)
+}
+
+// deleteImageUrls removes images no animal references any more. Callers
+// update the animal first so its own references are already gone.
+func (a *api) deleteImageUrls(ctx context.Context, urls []string) {
+	seen := map[string]bool{}
+
+	for _, url := range urls {
+		if seen[url] {
+			continue
+		}
+		seen[url] = true
+
+		refs, err := a.animalRepo.ImageReferences(ctx, url)
+		if err != nil {
+			a.logger.Warn("error counting image references", zap.String("url", url), zap.Error(err))
+			continue
+		}
+
+		if refs > 0 {
+			continue
+		}
+
+		key := a.imageStore.Key(url)
+
+		// An upload reusing the image refreshes it before its photo is
+		// saved; the cleanup job removes it later if that never happens.
+		if info, err := a.imageStore.Stat(ctx, key); err == nil && time.Since(info.ModTime) < imageReuseWindow {
+			continue
+		}
+
+		if err := a.imageStore.Delete(ctx, key); err != nil {
+			a.logger.Warn("error deleting image", zap.String("key", key), zap.Error(err))
+		}
+	}
+}
+
+// CleanupImages removes stored images that no animal references and that
+// are older than grace, which leaves uploads still being saved alone.
+func (a *api) CleanupImages(ctx context.Context, grace time.Duration) (int, error) {
+	referenced, err := a.animalRepo.ReferencedImageUrls(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	keys := map[string]bool{}
+	for url := range referenced {
+		keys[a.imageStore.Key(url)] = true
+	}
+
+	images, err := a.imageStore.List(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	cutoff := time.Now().Add(-grace)
+	removed := 0
+
+	for _, image := range images {
+		if keys[image.Key] || image.ModTime.After(cutoff) {
+			continue
+		}
+
+		// Listing can take a while; an upload may have reused the image
+		// since.
+		if info, err := a.imageStore.Stat(ctx, image.Key); err != nil || info.ModTime.After(cutoff) {
+			continue
+		}
+
+		if err := a.imageStore.Delete(ctx, image.Key); err != nil {
+			return removed, err
+		}
+
+		a.logger.Info("orphaned image removed", zap.String("key", image.Key), zap.Time("modified", image.ModTime))
+		removed++
+	}
+
+	return removed, nil
+}
+
+// ReprocessImages generates variants for photos uploaded before resizing
+// existed, or for every photo when force is set, replacing the stored
+// originals.
+func (a *api) ReprocessImages(ctx context.Context, force bool) (int, error) {
+	query := domain.AnimalQuery{Limit: domain.MaxAnimalLimit}
+	processed := 0
+
+	for {
+		page, err := a.animalRepo.Find(ctx, query)
+		if err != nil {
+			return processed, err
+		}
+
+		for _, animal := range page.Animals {
+			count, err := a.reprocessPhotos(ctx, animal, force)
+			if err != nil {
+				a.logger.Error("error reprocessing images", zap.String("animal", animal.Id.Hex()), zap.Error(err))
+			}
+			processed += count
+		}
+
+		if page.NextCursor == "" {
+			return processed, nil
+		}
+		query.Cursor = page.NextCursor
+	}
+}
+
+func (a *api) reprocessPhotos(ctx context.Context, animal *domain.Animal, force bool) (int, error) {
+	if len(animal.Photos) == 0 && animal.ImageUrl != "" {
+		var err error
+		if animal, err = a.loadGallery(ctx, animal.Id.Hex()); err != nil {
+			return 0, err
+		}
+	}
+
+	processed := 0
+
+	for _, photo := range animal.Photos {
+		if photo.Images != nil && !force {
+			continue
+		}
+
+		images, err := a.reprocessImage(ctx, photo.Url)
+		if err != nil {
+			a.logger.Error("error reprocessing image", zap.String("url", photo.Url), zap.Error(err))
+			continue
+		}
+
+		replaced := photo.URLs()
+		photo.Url = images.Full
+		photo.Images = images
+
+		if err := a.animalRepo.UpdatePhoto(ctx, animal.Id.Hex(), photo); err != nil {
+			a.deleteImageUrls(ctx, images.URLs())
+			return processed, err
+		}
+
+		a.deleteImageUrls(ctx, replaced)
+		processed++
+	}
+
+	return processed, nil
+}
+
+func (a *api) reprocessImage(ctx context.Context, url string) (*domain.ImageSet, error) {
+	original, _, err := a.imageStore.Get(ctx, a.imageStore.Key(url))
+	if err != nil {
+		return nil, err
+	}
+	defer original.Close()
+
+	return a.storeImage(ctx, original)
+}
+
+func (a *api) serveImage(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	image, info, err := a.imageStore.Get(r.Context(), r.URL.Path)
+	if errors.Is(err, domain.ErrImageNotFound) || errors.Is(err, storage.ErrInvalidKey) {
+		http.NotFound(w, r)
+		return
+	} else if err != nil {
+		a.errorResponse(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	defer image.Close()
+
+	if info.ContentType != "" {
+		w.Header().Set("Content-Type", info.ContentType)
+	}
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+
+	if seeker, ok := image.(io.ReadSeeker); ok {
+		http.ServeContent(w, r, r.URL.Path, info.ModTime, seeker)
+		return
+	}
+
+	if info.Size >= 0 {
+		w.Header().Set("Content-Length", strconv.FormatInt(info.Size, 10))
+	}
+	if !info.ModTime.IsZero() {
+		w.Header().Set("Last-Modified", info.ModTime.UTC().Format(http.TimeFormat))
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if r.Method == http.MethodGet {
+		io.Copy(w, image)
+	}
+}
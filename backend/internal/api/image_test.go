@@ -0,0 +1,113 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dspeirs7/animals/internal/storage"
+	"go.uber.org/zap"
+)
+
+func TestServeImage(t *testing.T) {
+	store, err := storage.NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body := "not really a jpeg"
+	if err := store.Put(context.Background(), "abc-full.jpg", strings.NewReader(body), int64(len(body)), "image/jpeg"); err != nil {
+		t.Fatal(err)
+	}
+
+	a := &api{logger: zap.NewNop(), imageStore: store}
+	handler := http.StripPrefix("/images/", http.HandlerFunc(a.serveImage))
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		status int
+	}{
+		{"existing", http.MethodGet, "/images/abc-full.jpg", http.StatusOK},
+		{"head", http.MethodHead, "/images/abc-full.jpg", http.StatusOK},
+		{"missing", http.MethodGet, "/images/missing.jpg", http.StatusNotFound},
+		{"empty key", http.MethodGet, "/images/", http.StatusNotFound},
+		{"trailing slash", http.MethodGet, "/images/abc-full.jpg/", http.StatusNotFound},
+		{"below a file", http.MethodGet, "/images/abc-full.jpg/x", http.StatusNotFound},
+		{"post", http.MethodPost, "/images/abc-full.jpg", http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+
+			if w.Code != tt.status {
+				t.Errorf("status = %d, want %d", w.Code, tt.status)
+			}
+		})
+	}
+}
+
+func TestStoreImageRefreshesReusedImages(t *testing.T) {
+	dir := t.TempDir()
+	store, err := storage.NewLocalStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	img := image.NewNRGBA(image.Rect(0, 0, 8, 8))
+	for i := range img.Pix {
+		img.Pix[i] = 0xFF
+	}
+	img.Set(1, 1, color.NRGBA{R: 200, A: 255})
+
+	var upload bytes.Buffer
+	if err := png.Encode(&upload, img); err != nil {
+		t.Fatal(err)
+	}
+
+	a := &api{logger: zap.NewNop(), imageStore: store}
+	ctx := context.Background()
+
+	first, err := a.storeImage(ctx, bytes.NewReader(upload.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	old := time.Now().Add(-48 * time.Hour)
+	for _, url := range first.URLs() {
+		if err := os.Chtimes(filepath.Join(dir, store.Key(url)), old, old); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	second, err := a.storeImage(ctx, bytes.NewReader(upload.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Join(second.URLs(), ",") != strings.Join(first.URLs(), ",") {
+		t.Fatalf("same upload stored as %v and %v", first.URLs(), second.URLs())
+	}
+
+	for _, url := range second.URLs() {
+		info, err := store.Stat(ctx, store.Key(url))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if time.Since(info.ModTime) > time.Hour {
+			t.Errorf("%s was reused without refreshing its modification time", url)
+		}
+	}
+}
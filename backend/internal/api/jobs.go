@@ -0,0 +1,247 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/dspeirs7/animals/internal/domain"
+	"github.com/dspeirs7/animals/internal/mail"
+	"github.com/dspeirs7/animals/internal/scheduler"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultDigestTime        = "07:00"
+	defaultDigestWithin      = 7 * 24 * time.Hour
+	defaultImageGracePeriod  = 24 * time.Hour
+	defaultImageCleanupEvery = 24 * time.Hour
+)
+
+func (a *api) registerJobs() {
+	if err := a.scheduler.Register("session-cleanup", scheduler.Every(15*time.Minute), a.cleanupSessions); err != nil {
+		a.logger.Fatal("error registering job", zap.Error(err))
+	}
+
+	grace, err := a.ImageGracePeriod()
+	if err != nil {
+		a.logger.Fatal("invalid IMAGE_GRACE_PERIOD", zap.Error(err))
+	}
+
+	cleanup := func(ctx context.Context) error {
+		removed, err := a.CleanupImages(ctx, grace)
+		if removed > 0 {
+			a.logger.Info("orphaned images removed", zap.Int("count", removed))
+		}
+		return err
+	}
+
+	if err := a.scheduler.Register("image-cleanup", scheduler.Every(defaultImageCleanupEvery), cleanup); err != nil {
+		a.logger.Fatal("error registering job", zap.Error(err))
+	}
+
+	retention, err := a.trashRetention()
+	if err != nil {
+		a.logger.Fatal("invalid TRASH_RETENTION", zap.Error(err))
+	}
+
+	purge := func(ctx context.Context) error {
+		purged, err := a.purgeTrash(ctx, retention)
+		if purged > 0 {
+			a.logger.Info("trashed animals purged", zap.Int("count", purged))
+		}
+		return err
+	}
+
+	if err := a.scheduler.Register("trash-purge", scheduler.Every(defaultTrashPurgeEvery), purge); err != nil {
+		a.logger.Fatal("error registering job", zap.Error(err))
+	}
+
+	mailer, err := mail.NewSMTPMailerFromEnv()
+	if errors.Is(err, mail.ErrNotConfigured) {
+		a.logger.Info("SMTP_HOST not set, vaccination digest disabled")
+		return
+	} else if err != nil {
+		a.logger.Fatal("error configuring mailer", zap.Error(err))
+	}
+
+	recipients := splitList(os.Getenv("DIGEST_TO"))
+	if len(recipients) == 0 {
+		a.logger.Info("DIGEST_TO not set, vaccination digest disabled")
+		return
+	}
+
+	clock := os.Getenv("DIGEST_TIME")
+	if clock == "" {
+		clock = defaultDigestTime
+	}
+
+	schedule, err := scheduler.ParseDaily(clock, a.location)
+	if err != nil {
+		a.logger.Fatal("invalid DIGEST_TIME", zap.Error(err))
+	}
+
+	within := defaultDigestWithin
+	if v := os.Getenv("DIGEST_WITHIN"); v != "" {
+		if within, err = parseWithin(v); err != nil {
+			a.logger.Fatal("invalid DIGEST_WITHIN", zap.Error(err))
+		}
+	}
+
+	digest := func(ctx context.Context) error {
+		return a.sendVaccinationDigest(ctx, mailer, recipients, within)
+	}
+
+	if err := a.scheduler.Register("vaccination-digest", schedule, digest); err != nil {
+		a.logger.Fatal("error registering job", zap.Error(err))
+	}
+}
+
+// ImageGracePeriod is how long an unreferenced image is kept, from
+// IMAGE_GRACE_PERIOD.
+func (a *api) ImageGracePeriod() (time.Duration, error) {
+	if v := os.Getenv("IMAGE_GRACE_PERIOD"); v != "" {
+		return parseWithin(v)
+	}
+	return defaultImageGracePeriod, nil
+}
+
+func (a *api) StartJobs(ctx context.Context) {
+	a.scheduler.Start(ctx)
+}
+
+// WaitJobs blocks until running jobs have finished after the context given
+// to StartJobs is cancelled.
+func (a *api) WaitJobs() {
+	a.scheduler.Wait()
+}
+
+func (a *api) cleanupSessions(ctx context.Context) error {
+	deleted, err := a.sessions.DeleteExpired(ctx)
+	if err != nil {
+		return err
+	}
+
+	if deleted > 0 {
+		a.logger.Info("expired sessions removed", zap.Int64("count", deleted))
+	}
+
+	return nil
+}
+
+// sendVaccinationDigest runs on every replica, so it first leases the farm
+// day and leaves the send to whichever replica gets there first. The lease
+// is released again when the send fails so a retry can take it.
+func (a *api) sendVaccinationDigest(ctx context.Context, mailer mail.Mailer, recipients []string, within time.Duration) (err error) {
+	now := time.Now()
+
+	lock := "vaccination-digest:" + now.In(a.location).Format("2006-01-02")
+	if err := a.lockRepo.Acquire(ctx, lock, now.Add(36*time.Hour)); errors.Is(err, domain.ErrLockHeld) {
+		a.logger.Info("vaccination digest already sent today", zap.String("lock", lock))
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			if releaseErr := a.lockRepo.Release(ctx, lock); releaseErr != nil {
+				a.logger.Error("error releasing digest lock", zap.Error(releaseErr))
+			}
+		}
+	}()
+
+	overdue, err := a.animalRepo.VaccinationsDue(ctx, domain.VaccinationDueQuery{To: now})
+	if err != nil {
+		return err
+	}
+
+	upcoming, err := a.animalRepo.VaccinationsDue(ctx, domain.VaccinationDueQuery{From: now, To: now.Add(within)})
+	if err != nil {
+		return err
+	}
+
+	if len(overdue) == 0 && len(upcoming) == 0 {
+		a.logger.Info("no vaccinations due, digest skipped")
+		return nil
+	}
+
+	msg := mail.Message{
+		To:      recipients,
+		Subject: fmt.Sprintf("Vaccinations: %d overdue, %d due soon", len(overdue), len(upcoming)),
+		Body:    vaccinationDigest(overdue, upcoming, a.typeNames(ctx), within),
+	}
+
+	return mailer.Send(ctx, msg)
+}
+
+func vaccinationDigest(overdue, upcoming []*domain.VaccinationDue, typeNames map[domain.AnimalType]string, within time.Duration) string {
+	var b strings.Builder
+
+	writeSection := func(title string, due []*domain.VaccinationDue) {
+		fmt.Fprintf(&b, "%s (%d)\n", title, len(due))
+		if len(due) == 0 {
+			b.WriteString("  none\n")
+		}
+		for _, d := range due {
+			fmt.Fprintf(&b, "  %s  %-20s %s (%s)\n", d.Vaccination.DateNeeded.Time().Format("2006-01-02"), d.Vaccination.Name, d.AnimalName, speciesName(typeNames, d.AnimalType))
+		}
+		b.WriteString("\n")
+	}
+
+	writeSection("Overdue", overdue)
+	writeSection(fmt.Sprintf("Due in the next %d days", int(within.Hours()/24)), upcoming)
+
+	return b.String()
+}
+
+// RunJob runs a registered job right away and waits for it to finish.
+func (a *api) RunJob(ctx context.Context, name string) error {
+	return a.scheduler.Run(ctx, name)
+}
+
+func (a *api) getJobs(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		a.jsonResponse(w, r, http.StatusOK, a.scheduler.Status())
+	case http.MethodOptions:
+		w.Header().Set("Allow", "GET, OPTIONS")
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.Header().Set("Allow", "GET, OPTIONS")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (a *api) runJob(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		if err := a.scheduler.Trigger(path.Base(r.URL.Path)); err != nil {
+			a.errorResponse(w, r, http.StatusNotFound, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusAccepted)
+	case http.MethodOptions:
+		w.Header().Set("Allow", "POST, OPTIONS")
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.Header().Set("Allow", "POST, OPTIONS")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func splitList(v string) []string {
+	var result []string
+	for _, part := range strings.Split(v, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			result = append(result, part)
+		}
+	}
+	return result
+}
@@ -0,0 +1,280 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dspeirs7/animals/internal/domain"
+	"github.com/dspeirs7/animals/internal/mail"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+)
+
+// smtpStandIn is a minimal SMTP server that records delivered messages.
+type smtpStandIn struct {
+	listener net.Listener
+
+	mu       sync.Mutex
+	messages []smtpDelivery
+}
+
+type smtpDelivery struct {
+	from string
+	to   []string
+	data string
+}
+
+func startSMTPStandIn(t *testing.T) *smtpStandIn {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := &smtpStandIn{listener: listener}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+
+	return s
+}
+
+func (s *smtpStandIn) serve(conn net.Conn) {
+	defer conn.Close()
+
+	r := bufio.NewReader(conn)
+	reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+
+	var msg smtpDelivery
+	reply("220 localhost ESMTP stand-in")
+
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		verb := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+
+		switch verb {
+		case "EHLO", "HELO":
+			reply("250 localhost")
+		case "MAIL":
+			msg = smtpDelivery{from: smtpPath(line)}
+			reply("250 OK")
+		case "RCPT":
+			msg.to = append(msg.to, smtpPath(line))
+			reply("250 OK")
+		case "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+
+			var data strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				data.WriteString(strings.TrimPrefix(l, "."))
+			}
+			msg.data = data.String()
+
+			s.mu.Lock()
+			s.messages = append(s.messages, msg)
+			s.mu.Unlock()
+
+			reply("250 OK")
+		case "RSET", "NOOP":
+			reply("250 OK")
+		case "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("502 Command not implemented")
+		}
+	}
+}
+
+func smtpPath(line string) string {
+	start, end := strings.Index(line, "<"), strings.LastIndex(line, ">")
+	if start < 0 || end < start {
+		return ""
+	}
+	return line[start+1 : end]
+}
+
+func (s *smtpStandIn) delivered() []smtpDelivery {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]smtpDelivery(nil), s.messages...)
+}
+
+type digestAnimalRepo struct {
+	domain.AnimalRepository
+	overdue, upcoming []*domain.VaccinationDue
+}
+
+func (d *digestAnimalRepo) VaccinationsDue(_ context.Context, query domain.VaccinationDueQuery) ([]*domain.VaccinationDue, error) {
+	if query.From.IsZero() {
+		return d.overdue, nil
+	}
+	return d.upcoming, nil
+}
+
+// digestSpeciesRepo is the default registry plus a type added at runtime.
+type digestSpeciesRepo struct {
+	domain.SpeciesRepository
+}
+
+func (digestSpeciesRepo) List(context.Context, bool) ([]*domain.Species, error) {
+	species := []*domain.Species{}
+	for i := range domain.DefaultSpecies {
+		species = append(species, &domain.DefaultSpecies[i])
+	}
+	species = append(species, &domain.Species{Id: 4, Slug: "ducks", Name: "Duck", Plural: "Ducks", Enabled: true, LaysEggs: true})
+	return species, nil
+}
+
+type memoryLockRepo struct {
+	mu    sync.Mutex
+	locks map[string]time.Time
+}
+
+func (m *memoryLockRepo) Acquire(_ context.Context, name string, expiry time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if held, ok := m.locks[name]; ok && held.After(time.Now()) {
+		return domain.ErrLockHeld
+	}
+	m.locks[name] = expiry
+	return nil
+}
+
+func (m *memoryLockRepo) Release(_ context.Context, name string) error {
+	m.mu.Lock()
+	delete(m.locks, name)
+	m.mu.Unlock()
+	return nil
+}
+
+func dueVaccination(name, animal string, animalType domain.AnimalType, needed time.Time) *domain.VaccinationDue {
+	return &domain.VaccinationDue{
+		AnimalId:    primitive.NewObjectID(),
+		AnimalName:  animal,
+		AnimalType:  animalType,
+		Vaccination: domain.Vaccination{Name: name, DateNeeded: primitive.NewDateTimeFromTime(needed)},
+	}
+}
+
+func TestVaccinationDigestSMTP(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name      string
+		repo      *digestAnimalRepo
+		wantSent  bool
+		subject   string
+		contains  []string
+		recipient []string
+	}{
+		{
+			name:     "nothing due",
+			repo:     &digestAnimalRepo{},
+			wantSent: false,
+		},
+		{
+			name: "overdue and upcoming",
+			repo: &digestAnimalRepo{
+				overdue: []*domain.VaccinationDue{dueVaccination("Rabies", "Tom", domain.CatType, now.AddDate(0, 0, -3))},
+				upcoming: []*domain.VaccinationDue{
+					dueVaccination("Marek's", "Henrietta", domain.ChickenType, now.AddDate(0, 0, 2)),
+					dueVaccination("DHPP", "Rex", domain.DogType, now.AddDate(0, 0, 5)),
+					dueVaccination("Duck viral enteritis", "Daffy", 4, now.AddDate(0, 0, 6)),
+				},
+			},
+			wantSent:  true,
+			subject:   "Subject: Vaccinations: 1 overdue, 3 due soon\r\n",
+			contains:  []string{"Overdue (1)\r\n", "Tom (Cat)", "Due in the next 7 days (3)\r\n", "Henrietta (Chicken)", "Rex (Dog)", "Daffy (Duck)"},
+			recipient: []string{"vet@farm.example", "owner@farm.example"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := startSMTPStandIn(t)
+			host, port, _ := net.SplitHostPort(server.listener.Addr().String())
+
+			t.Setenv("SMTP_HOST", host)
+			t.Setenv("SMTP_PORT", port)
+			t.Setenv("SMTP_FROM", "animals@farm.example")
+
+			mailer, err := mail.NewSMTPMailerFromEnv()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			a := &api{
+				logger:      zap.NewNop(),
+				animalRepo:  tt.repo,
+				speciesRepo: digestSpeciesRepo{},
+				lockRepo:    &memoryLockRepo{locks: map[string]time.Time{}},
+				location:    time.UTC,
+			}
+
+			recipients := []string{"vet@farm.example", "owner@farm.example"}
+			if err := a.sendVaccinationDigest(context.Background(), mailer, recipients, defaultDigestWithin); err != nil {
+				t.Fatal(err)
+			}
+
+			// A second replica running the same job must not send it again.
+			if err := a.sendVaccinationDigest(context.Background(), mailer, recipients, defaultDigestWithin); err != nil {
+				t.Fatal(err)
+			}
+
+			delivered := server.delivered()
+			if !tt.wantSent {
+				if len(delivered) != 0 {
+					t.Fatalf("got %d messages, want none", len(delivered))
+				}
+				return
+			}
+
+			if len(delivered) != 1 {
+				t.Fatalf("got %d messages, want 1", len(delivered))
+			}
+
+			msg := delivered[0]
+			if msg.from != "animals@farm.example" {
+				t.Errorf("from = %q", msg.from)
+			}
+			if strings.Join(msg.to, ",") != strings.Join(tt.recipient, ",") {
+				t.Errorf("to = %v, want %v", msg.to, tt.recipient)
+			}
+			if !strings.Contains(msg.data, tt.subject) {
+				t.Errorf("message is missing %q:\n%s", tt.subject, msg.data)
+			}
+			for _, want := range tt.contains {
+				if !strings.Contains(msg.data, want) {
+					t.Errorf("message is missing %q:\n%s", want, msg.data)
+				}
+			}
+		})
+	}
+}
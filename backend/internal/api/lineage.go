@@ -0,0 +1,346 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"path"
+	"strconv"
+
+	"github.com/dspeirs7/animals/internal/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+)
+
+// getPedigree serves /api/animal/{id}/pedigree?depth=N, the animal's
+// ancestors nested as sire and dam.
+func (a *api) getPedigree(w http.ResponseWriter, r *http.Request) {
+	a.familyTree(w, r, true)
+}
+
+// getDescendants serves /api/animal/{id}/descendants?depth=N, the animal's
+// offspring nested as children.
+func (a *api) getDescendants(w http.ResponseWriter, r *http.Request) {
+	a.familyTree(w, r, false)
+}
+
+func (a *api) familyTree(w http.ResponseWriter, r *http.Request, ancestors bool) {
+	switch r.Method {
+	case http.MethodGet:
+		ctx, cancel := context.WithCancel(r.Context())
+		defer cancel()
+
+		animal := r.Context().Value("animal").(*domain.Animal)
+
+		depth := domain.DefaultPedigreeDepth
+		if v := r.URL.Query().Get("depth"); v != "" {
+			d, err := strconv.Atoi(v)
+			if err != nil || d < 1 || d > domain.MaxPedigreeDepth {
+				a.errorResponse(w, r, http.StatusBadRequest, domain.ErrInvalidDepth)
+				return
+			}
+			depth = d
+		}
+
+		var tree *domain.FamilyNode
+		if ancestors {
+			relatives, err := a.animalRepo.Ancestors(ctx, animal.Id, depth)
+			if err != nil {
+				a.errorResponse(w, r, lineageErrorStatus(err), err)
+				return
+			}
+			tree = domain.Pedigree(animal, relatives, depth)
+		} else {
+			relatives, err := a.animalRepo.Descendants(ctx, animal.Id, depth)
+			if err != nil {
+				a.errorResponse(w, r, lineageErrorStatus(err), err)
+				return
+			}
+			tree = domain.Descendants(animal, relatives, depth)
+		}
+
+		a.jsonResponse(w, r, http.StatusOK, tree)
+	case http.MethodOptions:
+		w.Header().Set("Allow", "GET, OPTIONS")
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.Header().Set("Allow", "GET, OPTIONS")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (a *api) getLitters(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	switch r.Method {
+	case http.MethodGet:
+		var animalType domain.AnimalType
+		if v := r.URL.Query().Get("type"); v != "" {
+			t, err := strconv.Atoi(v)
+			if err != nil {
+				a.errorResponse(w, r, http.StatusBadRequest, err)
+				return
+			}
+			animalType = domain.AnimalType(t)
+		}
+
+		litters, err := a.litterRepo.List(ctx, animalType)
+		if err != nil {
+			a.errorResponse(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		a.jsonResponse(w, r, http.StatusOK, litters)
+	case http.MethodPost:
+		var litter domain.Litter
+		if err := json.NewDecoder(r.Body).Decode(&litter); err != nil {
+			a.errorResponse(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		if err := a.validateLitter(ctx, &litter, nil, nil); err != nil {
+			a.errorResponse(w, r, lineageErrorStatus(err), err)
+			return
+		}
+
+		result, err := a.litterRepo.Insert(ctx, litter)
+		if err != nil {
+			a.errorResponse(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		a.jsonResponse(w, r, http.StatusCreated, result)
+	case http.MethodOptions:
+		w.Header().Set("Allow", "GET, POST, OPTIONS")
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.Header().Set("Allow", "GET, POST, OPTIONS")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleLitter serves /api/litter/{id}. Changing a litter's parents changes
+// them on every member too.
+func (a *api) handleLitter(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	id := path.Base(r.URL.Path)
+
+	switch r.Method {
+	case http.MethodGet:
+		litter, err := a.litterRepo.GetById(ctx, id)
+		if err != nil {
+			a.errorResponse(w, r, lineageErrorStatus(err), err)
+			return
+		}
+
+		if litter.Members, err = a.animalRepo.LitterMembers(ctx, litter.Id, false); err != nil {
+			a.errorResponse(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		a.jsonResponse(w, r, http.StatusOK, litter)
+	case http.MethodPut:
+		existing, err := a.litterRepo.GetById(ctx, id)
+		if err != nil {
+			a.errorResponse(w, r, lineageErrorStatus(err), err)
+			return
+		}
+
+		var litter domain.Litter
+		if err := json.NewDecoder(r.Body).Decode(&litter); err != nil {
+			a.errorResponse(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		// Members in the trash come back with the litter's parents, so they
+		// are checked too.
+		members, err := a.animalRepo.LitterMembers(ctx, existing.Id, true)
+		if err != nil {
+			a.errorResponse(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		if len(members) > 0 && litter.Type != existing.Type {
+			a.errorResponse(w, r, http.StatusBadRequest, domain.ErrParentSpecies)
+			return
+		}
+
+		if err := a.validateLitter(ctx, &litter, members, existing); err != nil {
+			a.errorResponse(w, r, lineageErrorStatus(err), err)
+			return
+		}
+
+		if err := a.litterRepo.Update(ctx, id, litter); err != nil {
+			a.errorResponse(w, r, lineageErrorStatus(err), err)
+			return
+		}
+
+		litter.Id = existing.Id
+		if err := a.animalRepo.SetLitterParents(ctx, &litter); err != nil {
+			a.errorResponse(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	case http.MethodDelete:
+		litter, err := a.litterRepo.GetById(ctx, id)
+		if err != nil {
+			a.errorResponse(w, r, lineageErrorStatus(err), err)
+			return
+		}
+
+		if err := a.litterRepo.Delete(ctx, id); err != nil {
+			a.errorResponse(w, r, lineageErrorStatus(err), err)
+			return
+		}
+
+		// Members keep their parents, they just no longer share a litter.
+		if err := a.animalRepo.ClearLitter(ctx, litter.Id); err != nil {
+			a.logger.Warn("error clearing litter", zap.String("litter", id), zap.Error(err))
+		}
+
+		w.WriteHeader(http.StatusOK)
+	case http.MethodOptions:
+		w.Header().Set("Allow", "GET, PUT, DELETE, OPTIONS")
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.Header().Set("Allow", "GET, PUT, DELETE, OPTIONS")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// validateLineage fills in an animal's parents from its litter and checks
+// them: they must exist, be the same type of animal, be of the right sex
+// and not descend from the animal itself. existing is nil for new animals.
+func (a *api) validateLineage(ctx context.Context, existing *domain.Animal, animal *domain.Animal) error {
+	var sireId, damId *primitive.ObjectID
+	if existing != nil {
+		animal.Id = existing.Id
+		if existing.Type == animal.Type {
+			sireId, damId = existing.SireId, existing.DamId
+		}
+	} else {
+		animal.Id = primitive.NilObjectID
+	}
+
+	if animal.LitterId != nil {
+		litter, err := a.litterRepo.GetById(ctx, animal.LitterId.Hex())
+		if err != nil {
+			return err
+		}
+
+		if err := litter.ApplyTo(animal); err != nil {
+			return err
+		}
+	}
+
+	if err := a.checkParents(ctx, []*domain.Animal{animal}, animal.Type, animal.SireId, animal.DamId, sireId, damId); err != nil {
+		return err
+	}
+
+	animal.SyncParents()
+
+	return nil
+}
+
+// validateLitter checks a litter's parents against the litter's type and
+// its current members. existing is nil for new litters.
+func (a *api) validateLitter(ctx context.Context, litter *domain.Litter, members []*domain.Animal, existing *domain.Litter) error {
+	if err := litter.Validate(); err != nil {
+		return err
+	}
+
+	var sireId, damId *primitive.ObjectID
+	if existing != nil && existing.Type == litter.Type {
+		sireId, damId = existing.SireId, existing.DamId
+	}
+
+	return a.checkParents(ctx, members, litter.Type, litter.SireId, litter.DamId, sireId, damId)
+}
+
+// checkParents checks the sire and dam being set on children. Parents they
+// already had, given as oldSireId and oldDamId, aren't checked again, so a
+// parent that has since been trashed doesn't block other edits.
+func (a *api) checkParents(ctx context.Context, children []*domain.Animal, animalType domain.AnimalType, sireId, damId, oldSireId, oldDamId *primitive.ObjectID) error {
+	if sireId != nil && damId != nil && *sireId == *damId {
+		return domain.ErrSameParents
+	}
+
+	parents := []struct {
+		id, old *primitive.ObjectID
+		sire    bool
+	}{{sireId, oldSireId, true}, {damId, oldDamId, false}}
+
+	for _, p := range parents {
+		if p.id == nil || p.old != nil && *p.old == *p.id {
+			continue
+		}
+
+		parent, err := a.animalRepo.GetById(ctx, p.id.Hex())
+		if err != nil {
+			return err
+		}
+
+		if parent.Id.IsZero() {
+			return domain.ErrAnimalNotFound
+		}
+
+		if err := domain.CheckParent(animalType, parent, p.sire); err != nil {
+			return err
+		}
+
+		for _, child := range children {
+			if child.Id.IsZero() {
+				continue
+			}
+
+			if parent.Id == child.Id {
+				return domain.ErrOwnParent
+			}
+
+			descendants, err := a.animalRepo.DescendantIds(ctx, child.Id)
+			if err != nil {
+				return err
+			}
+
+			for _, descendant := range descendants {
+				if descendant == parent.Id {
+					return domain.ErrParentCycle
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// animalLineageStatus is lineageErrorStatus for animal writes, where an
+// unknown litter is a bad request rather than a missing resource.
+func animalLineageStatus(err error) int {
+	if errors.Is(err, domain.ErrLitterNotFound) {
+		return http.StatusBadRequest
+	}
+	return lineageErrorStatus(err)
+}
+
+func lineageErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrLitterNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrAnimalNotFound),
+		errors.Is(err, domain.ErrOwnParent),
+		errors.Is(err, domain.ErrSameParents),
+		errors.Is(err, domain.ErrParentCycle),
+		errors.Is(err, domain.ErrParentSpecies),
+		errors.Is(err, domain.ErrSireSex),
+		errors.Is(err, domain.ErrDamSex),
+		errors.Is(err, domain.ErrLitterParents),
+		errors.Is(err, domain.ErrMissingLitterOf):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
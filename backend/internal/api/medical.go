@@ -0,0 +1,224 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"path"
+	"time"
+
+	"github.com/dspeirs7/animals/internal/domain"
+	"github.com/dspeirs7/animals/internal/middleware"
+	"go.uber.org/zap"
+)
+
+var errVisitMismatch = errors.New("visitId must be a visit of the same animal")
+
+// animalMedical serves /api/animal/{id}/medical: the animal's medical
+// timeline, and new records for it.
+func (a *api) animalMedical(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	animal := r.Context().Value("animal").(*domain.Animal)
+
+	switch r.Method {
+	case http.MethodGet:
+		records, err := a.medicalRepo.ForAnimal(ctx, animal.Id)
+		if err != nil {
+			a.errorResponse(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		a.jsonResponse(w, r, http.StatusOK, domain.MedicalTimeline(animal, records))
+	case http.MethodPost:
+		var record domain.MedicalRecord
+		if err := json.NewDecoder(r.Body).Decode(&record); err != nil {
+			a.errorResponse(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		record.AnimalId = animal.Id
+		if session, ok := middleware.SessionFromContext(ctx); ok {
+			record.RecordedBy = session.Username
+		}
+
+		if err := a.validateMedicalRecord(ctx, &record); err != nil {
+			a.errorResponse(w, r, medicalErrorStatus(err), err)
+			return
+		}
+
+		result, err := a.medicalRepo.Insert(ctx, record)
+		if err != nil {
+			a.errorResponse(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		a.jsonResponse(w, r, http.StatusCreated, result)
+	case http.MethodOptions:
+		w.Header().Set("Allow", "GET, POST, OPTIONS")
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.Header().Set("Allow", "GET, POST, OPTIONS")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (a *api) handleMedicalRecord(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	id := path.Base(r.URL.Path)
+
+	switch r.Method {
+	case http.MethodGet:
+		record, err := a.medicalRepo.GetById(ctx, id)
+		if err != nil {
+			a.errorResponse(w, r, medicalErrorStatus(err), err)
+			return
+		}
+
+		a.jsonResponse(w, r, http.StatusOK, record)
+	case http.MethodPut:
+		existing, err := a.medicalRepo.GetById(ctx, id)
+		if err != nil {
+			a.errorResponse(w, r, medicalErrorStatus(err), err)
+			return
+		}
+
+		var record domain.MedicalRecord
+		if err := json.NewDecoder(r.Body).Decode(&record); err != nil {
+			a.errorResponse(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		// Records can't be moved to another animal.
+		record.AnimalId = existing.AnimalId
+		record.RecordedBy = existing.RecordedBy
+
+		if err := a.validateMedicalRecord(ctx, &record); err != nil {
+			a.errorResponse(w, r, medicalErrorStatus(err), err)
+			return
+		}
+
+		if err := a.medicalRepo.Update(ctx, id, record); err != nil {
+			a.errorResponse(w, r, medicalErrorStatus(err), err)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	case http.MethodDelete:
+		record, err := a.medicalRepo.GetById(ctx, id)
+		if err != nil {
+			a.errorResponse(w, r, medicalErrorStatus(err), err)
+			return
+		}
+
+		if err := a.medicalRepo.Delete(ctx, id); err != nil {
+			a.errorResponse(w, r, medicalErrorStatus(err), err)
+			return
+		}
+
+		switch record.Kind {
+		case domain.MedicalMedication:
+			if err := a.doseRepo.DeleteForMedication(ctx, record.Id); err != nil {
+				a.logger.Warn("error deleting doses", zap.String("medication", id), zap.Error(err))
+			}
+		case domain.MedicalVisit:
+			if err := a.medicalRepo.UnlinkVisit(ctx, record.Id); err != nil {
+				a.errorResponse(w, r, http.StatusInternalServerError, err)
+				return
+			}
+		}
+
+		w.WriteHeader(http.StatusOK)
+	case http.MethodOptions:
+		w.Header().Set("Allow", "GET, PUT, DELETE, OPTIONS")
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.Header().Set("Allow", "GET, PUT, DELETE, OPTIONS")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// getActiveMedications lists the medication courses running today, with
+// the animals they are for. ?type= limits it to some animal types.
+func (a *api) getActiveMedications(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	switch r.Method {
+	case http.MethodGet:
+		types, err := parseInts(r.URL.Query(), "type")
+		if err != nil {
+			a.errorResponse(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		var animalTypes []domain.AnimalType
+		for _, t := range types {
+			animalTypes = append(animalTypes, domain.AnimalType(t))
+		}
+
+		now := time.Now()
+		medications, err := a.medicalRepo.ActiveMedications(ctx, now, now, animalTypes)
+		if err != nil {
+			a.errorResponse(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		a.jsonResponse(w, r, http.StatusOK, medications)
+	case http.MethodOptions:
+		w.Header().Set("Allow", "GET, OPTIONS")
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.Header().Set("Allow", "GET, OPTIONS")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// validateMedicalRecord checks the record and that the visit it refers to,
+// if any, belongs to the same animal.
+func (a *api) validateMedicalRecord(ctx context.Context, record *domain.MedicalRecord) error {
+	if err := record.Validate(); err != nil {
+		return err
+	}
+
+	if record.VisitId == nil {
+		return nil
+	}
+
+	visit, err := a.medicalRepo.GetById(ctx, record.VisitId.Hex())
+	if err != nil {
+		if errors.Is(err, domain.ErrMedicalRecordNotFound) {
+			return errVisitMismatch
+		}
+		return err
+	}
+
+	if visit.Kind != domain.MedicalVisit || visit.AnimalId != record.AnimalId {
+		return errVisitMismatch
+	}
+
+	return nil
+}
+
+func medicalErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrMedicalRecordNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, errVisitMismatch),
+		errors.Is(err, domain.ErrInvalidMedicalKind),
+		errors.Is(err, domain.ErrMissingMedicalDate),
+		errors.Is(err, domain.ErrMissingClinic),
+		errors.Is(err, domain.ErrInvalidCost),
+		errors.Is(err, domain.ErrMissingCondition),
+		errors.Is(err, domain.ErrMissingDrug),
+		errors.Is(err, domain.ErrInvalidFrequency),
+		errors.Is(err, domain.ErrInvalidTimeOfDay),
+		errors.Is(err, domain.ErrInvalidCourseEnd):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,285 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/dspeirs7/animals/internal/domain"
+	"github.com/dspeirs7/animals/internal/middleware"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	galleryMethods = "GET, POST, PUT, OPTIONS"
+	photoMethods   = "GET, PUT, DELETE, OPTIONS"
+)
+
+type photoUpdate struct {
+	Caption   *string             `json:"caption,omitempty"`
+	TakenAt   *primitive.DateTime `json:"takenAt,omitempty"`
+	IsPrimary *bool               `json:"isPrimary,omitempty"`
+}
+
+// handlePhotos serves /api/photos/{animalId} for listing, adding and
+// reordering photos, and /api/photos/{animalId}/{photoId} for editing and
+// deleting a single photo.
+func (a *api) handlePhotos(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	ids := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/photos/"), "/"), "/")
+	if len(ids) > 2 || ids[0] == "" {
+		http.NotFound(w, r)
+		return
+	}
+
+	if r.Method == http.MethodOptions {
+		if len(ids) == 1 {
+			w.Header().Set("Allow", galleryMethods)
+		} else {
+			w.Header().Set("Allow", photoMethods)
+		}
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	animal, err := a.loadGallery(ctx, ids[0])
+	if err != nil {
+		a.errorResponse(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	if animal.Id.IsZero() {
+		a.errorResponse(w, r, http.StatusNotFound, errors.New("animal not found"))
+		return
+	}
+
+	if len(ids) == 1 {
+		a.handleGallery(ctx, w, r, animal)
+		return
+	}
+
+	photoId, err := primitive.ObjectIDFromHex(ids[1])
+	if err != nil {
+		a.errorResponse(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	a.handlePhoto(ctx, w, r, animal, photoId)
+}
+
+func (a *api) handleGallery(ctx context.Context, w http.ResponseWriter, r *http.Request, animal *domain.Animal) {
+	switch r.Method {
+	case http.MethodGet:
+		photos := animal.Photos
+		if photos == nil {
+			photos = []domain.Photo{}
+		}
+
+		a.jsonResponse(w, r, http.StatusOK, photos)
+	case http.MethodPost:
+		photo, status, err := a.addPhoto(ctx, w, r, animal, false)
+		if err != nil {
+			a.errorResponse(w, r, status, err)
+			return
+		}
+
+		a.jsonResponse(w, r, http.StatusCreated, photo)
+	case http.MethodPut:
+		var order []primitive.ObjectID
+		if err := json.NewDecoder(r.Body).Decode(&order); err != nil {
+			a.errorResponse(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		if err := animal.ValidatePhotoOrder(order); err != nil {
+			a.errorResponse(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		// The gallery changed since it was read when the order no longer
+		// matches it.
+		if err := a.animalRepo.ReorderPhotos(ctx, animal.Id.Hex(), order); errors.Is(err, domain.ErrPhotoOrder) {
+			a.errorResponse(w, r, http.StatusConflict, err)
+			return
+		} else if err != nil {
+			a.errorResponse(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		updated, err := a.animalRepo.GetById(ctx, animal.Id.Hex())
+		if err != nil {
+			a.errorResponse(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		a.jsonResponse(w, r, http.StatusOK, updated.Photos)
+	default:
+		w.Header().Set("Allow", galleryMethods)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (a *api) handlePhoto(ctx context.Context, w http.ResponseWriter, r *http.Request, animal *domain.Animal, photoId primitive.ObjectID) {
+	photo := animal.Photo(photoId)
+	if photo == nil {
+		a.errorResponse(w, r, http.StatusNotFound, domain.ErrPhotoNotFound)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		a.jsonResponse(w, r, http.StatusOK, photo)
+	case http.MethodPut:
+		var update photoUpdate
+		if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+			a.errorResponse(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		if update.Caption != nil || update.TakenAt != nil {
+			edited := *photo
+			if update.Caption != nil {
+				edited.Caption = strings.TrimSpace(*update.Caption)
+			}
+			if update.TakenAt != nil {
+				edited.TakenAt = *update.TakenAt
+			}
+
+			if err := a.animalRepo.UpdatePhoto(ctx, animal.Id.Hex(), edited); err != nil {
+				a.errorResponse(w, r, photoErrorStatus(err), err)
+				return
+			}
+		}
+
+		if update.IsPrimary != nil && *update.IsPrimary {
+			if err := a.animalRepo.SetPrimaryPhoto(ctx, animal.Id.Hex(), photoId); err != nil {
+				a.errorResponse(w, r, photoErrorStatus(err), err)
+				return
+			}
+		}
+
+		updated, err := a.animalRepo.GetById(ctx, animal.Id.Hex())
+		if err != nil {
+			a.errorResponse(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		photo = updated.Photo(photoId)
+		if photo == nil {
+			a.errorResponse(w, r, http.StatusNotFound, domain.ErrPhotoNotFound)
+			return
+		}
+
+		a.jsonResponse(w, r, http.StatusOK, photo)
+	case http.MethodDelete:
+		if err := a.animalRepo.RemovePhoto(ctx, animal.Id.Hex(), photoId); err != nil {
+			a.errorResponse(w, r, photoErrorStatus(err), err)
+			return
+		}
+
+		a.deleteImageUrls(ctx, photo.URLs())
+
+		w.WriteHeader(http.StatusOK)
+	default:
+		w.Header().Set("Allow", photoMethods)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// addPhoto stores the multipart "image" upload, with optional "caption",
+// "takenAt" and "isPrimary" fields, as a new photo of the animal. The body
+// is capped at maxUploadBytes and the image type is sniffed from its
+// content, never trusted from the client.
+func (a *api) addPhoto(ctx context.Context, w http.ResponseWriter, r *http.Request, animal *domain.Animal, primary bool) (*domain.Photo, int, error) {
+	r.Body = http.MaxBytesReader(w, r.Body, a.maxUploadBytes)
+
+	if err := r.ParseMultipartForm(a.maxUploadBytes); err != nil {
+		return nil, uploadErrorStatus(err), err
+	}
+
+	file, _, err := r.FormFile("image")
+	if err != nil {
+		return nil, uploadErrorStatus(err), err
+	}
+
+	defer file.Close()
+
+	if r.FormValue("isPrimary") == "true" {
+		primary = true
+	}
+
+	photo := domain.Photo{
+		Id:         primitive.NewObjectID(),
+		Caption:    strings.TrimSpace(r.FormValue("caption")),
+		UploadedAt: primitive.NewDateTimeFromTime(time.Now()),
+		IsPrimary:  primary,
+	}
+
+	if session, ok := middleware.SessionFromContext(ctx); ok {
+		photo.UploadedBy = session.Username
+	}
+
+	if v := r.FormValue("takenAt"); v != "" {
+		takenAt, err := parseDate(v)
+		if err != nil {
+			return nil, http.StatusBadRequest, err
+		}
+		photo.TakenAt = primitive.NewDateTimeFromTime(takenAt)
+	}
+
+	images, err := a.storeImage(ctx, file)
+	if err != nil {
+		return nil, uploadErrorStatus(err), err
+	}
+
+	photo.Url = images.Full
+	photo.Images = images
+
+	if err := a.animalRepo.AddPhoto(ctx, animal.Id.Hex(), photo); err != nil {
+		a.deleteImageUrls(ctx, images.URLs())
+		return nil, photoErrorStatus(err), err
+	}
+
+	updated, err := a.animalRepo.GetById(ctx, animal.Id.Hex())
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	if added := updated.Photo(photo.Id); added != nil {
+		return added, http.StatusOK, nil
+	}
+
+	return &photo, http.StatusOK, nil
+}
+
+// loadGallery reads the animal, first saving an ImageUrl uploaded before
+// galleries existed as its first photo so the photo's id is stable.
+func (a *api) loadGallery(ctx context.Context, id string) (*domain.Animal, error) {
+	animal, err := a.animalRepo.GetById(ctx, id)
+	if err != nil || animal.Id.IsZero() || len(animal.Photos) > 0 || animal.ImageUrl == "" {
+		return animal, err
+	}
+
+	animal.MaterializeLegacyPhoto()
+
+	if err := a.animalRepo.MaterializeLegacyPhoto(ctx, id, animal.Photos[0]); err != nil {
+		return nil, err
+	}
+
+	return a.animalRepo.GetById(ctx, id)
+}
+
+func photoErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrPhotoNotFound), errors.Is(err, domain.ErrAnimalNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrPhotoOrder):
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,216 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/dspeirs7/animals/internal/domain"
+)
+
+func (a *api) getProtocols(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	switch r.Method {
+	case http.MethodGet:
+		var animalType domain.AnimalType
+		if v := r.URL.Query().Get("type"); v != "" {
+			t, err := strconv.Atoi(v)
+			if err != nil {
+				a.errorResponse(w, r, http.StatusBadRequest, err)
+				return
+			}
+			animalType = domain.AnimalType(t)
+		}
+
+		protocols, err := a.protocolRepo.GetAll(ctx, animalType)
+		if err != nil {
+			a.errorResponse(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		a.jsonResponse(w, r, http.StatusOK, protocols)
+	case http.MethodPost:
+		var protocol domain.VaccinationProtocol
+		if err := json.NewDecoder(r.Body).Decode(&protocol); err != nil {
+			a.errorResponse(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		if err := protocol.Validate(); err != nil {
+			a.errorResponse(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		if _, err := a.validateType(ctx, protocol.Type, false); err != nil {
+			a.errorResponse(w, r, speciesErrorStatus(err), err)
+			return
+		}
+
+		result, err := a.protocolRepo.Insert(ctx, protocol)
+		if err != nil {
+			a.errorResponse(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		a.jsonResponse(w, r, http.StatusCreated, result)
+	case http.MethodOptions:
+		w.Header().Set("Allow", "GET, POST, OPTIONS")
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.Header().Set("Allow", "GET, POST, OPTIONS")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (a *api) handleProtocol(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	id := path.Base(r.URL.Path)
+
+	switch r.Method {
+	case http.MethodGet:
+		protocol, err := a.protocolRepo.GetById(ctx, id)
+		if err != nil {
+			a.errorResponse(w, r, protocolErrorStatus(err), err)
+			return
+		}
+
+		a.jsonResponse(w, r, http.StatusOK, protocol)
+	case http.MethodPut:
+		var protocol domain.VaccinationProtocol
+		if err := json.NewDecoder(r.Body).Decode(&protocol); err != nil {
+			a.errorResponse(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		if err := protocol.Validate(); err != nil {
+			a.errorResponse(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		if _, err := a.validateType(ctx, protocol.Type, false); err != nil {
+			a.errorResponse(w, r, speciesErrorStatus(err), err)
+			return
+		}
+
+		if err := a.protocolRepo.Update(ctx, id, protocol); err != nil {
+			a.errorResponse(w, r, protocolErrorStatus(err), err)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	case http.MethodDelete:
+		if err := a.protocolRepo.Delete(ctx, id); err != nil {
+			a.errorResponse(w, r, protocolErrorStatus(err), err)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	case http.MethodOptions:
+		w.Header().Set("Allow", "GET, PUT, DELETE, OPTIONS")
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.Header().Set("Allow", "GET, PUT, DELETE, OPTIONS")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (a *api) backfillProtocol(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		ctx, cancel := context.WithCancel(r.Context())
+		defer cancel()
+
+		protocol, err := a.protocolRepo.GetById(ctx, path.Base(r.URL.Path))
+		if err != nil {
+			a.errorResponse(w, r, protocolErrorStatus(err), err)
+			return
+		}
+
+		query := domain.AnimalQuery{Types: []domain.AnimalType{protocol.Type}, Limit: domain.MaxAnimalLimit}
+		if protocol.Breed != 0 {
+			query.Breeds = []domain.AnimalBreed{protocol.Breed}
+		}
+
+		now := time.Now()
+		updated := 0
+
+		for {
+			page, err := a.animalRepo.Find(ctx, query)
+			if err != nil {
+				a.errorResponse(w, r, http.StatusInternalServerError, err)
+				return
+			}
+
+			for _, animal := range page.Animals {
+				pending, ok := protocol.Backfill(animal, now)
+				if !ok {
+					continue
+				}
+
+				if err := a.animalRepo.AddVaccinations(ctx, animal.Id.Hex(), []domain.Vaccination{pending}); err != nil {
+					a.errorResponse(w, r, http.StatusInternalServerError, err)
+					return
+				}
+				updated++
+			}
+
+			if page.NextCursor == "" {
+				break
+			}
+			query.Cursor = page.NextCursor
+		}
+
+		a.jsonResponse(w, r, http.StatusOK, map[string]int{"updated": updated})
+	case http.MethodOptions:
+		w.Header().Set("Allow", "POST, OPTIONS")
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.Header().Set("Allow", "POST, OPTIONS")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// withBoosters appends the next scheduled dose for every given vaccination
+// covered by a protocol, unless the caller already set DateNeeded by hand.
+func (a *api) withBoosters(ctx context.Context, animal *domain.Animal, vaccinations []domain.Vaccination) ([]domain.Vaccination, error) {
+	protocols, err := a.protocolRepo.GetAll(ctx, animal.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	result := vaccinations
+	for _, v := range vaccinations {
+		if v.DateGiven == 0 || v.DateNeeded != 0 {
+			continue
+		}
+
+		protocol := domain.ProtocolFor(protocols, animal, v)
+		if protocol == nil {
+			continue
+		}
+
+		if next, ok := protocol.NextDose(v); ok {
+			result = append(result, next)
+		}
+	}
+
+	return result, nil
+}
+
+func protocolErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrProtocolNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrInvalidProtocolId):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,269 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dspeirs7/animals/internal/domain"
+)
+
+func parseAnimalQuery(values url.Values) (domain.AnimalQuery, error) {
+	query := domain.AnimalQuery{
+		NamePrefix: values.Get("name"),
+		Sort:       values.Get("sort"),
+		Cursor:     values.Get("cursor"),
+	}
+
+	types, err := parseInts(values, "type")
+	if err != nil {
+		return query, err
+	}
+	for _, t := range types {
+		query.Types = append(query.Types, domain.AnimalType(t))
+	}
+
+	breeds, err := parseInts(values, "breed")
+	if err != nil {
+		return query, err
+	}
+	for _, b := range breeds {
+		query.Breeds = append(query.Breeds, domain.AnimalBreed(b))
+	}
+
+	for _, v := range values["sex"] {
+		for _, sex := range strings.Split(v, ",") {
+			if sex == "" {
+				continue
+			}
+			if !domain.Sex(sex).Valid() {
+				return query, domain.ErrInvalidSex
+			}
+			query.Sexes = append(query.Sexes, domain.Sex(sex))
+		}
+	}
+
+	for _, v := range values["status"] {
+		for _, status := range strings.Split(v, ",") {
+			if status == "" {
+				continue
+			}
+			if !domain.Status(status).Valid() {
+				return query, domain.ErrInvalidStatus
+			}
+			query.Statuses = append(query.Statuses, domain.Status(status))
+		}
+	}
+
+	if v := values.Get("altered"); v != "" {
+		altered, err := strconv.ParseBool(v)
+		if err != nil {
+			return query, fmt.Errorf("invalid altered: %w", err)
+		}
+		query.Altered = &altered
+	}
+
+	now := time.Now()
+
+	if v := values.Get("minAge"); v != "" {
+		born, err := bornBefore(now, v)
+		if err != nil {
+			return query, err
+		}
+		query.BornBefore = born
+	}
+
+	if v := values.Get("maxAge"); v != "" {
+		born, err := bornBefore(now, v)
+		if err != nil {
+			return query, err
+		}
+		query.BornAfter = born
+	}
+
+	if v := values.Get("vaccinated"); v != "" {
+		vaccinated, err := strconv.ParseBool(v)
+		if err != nil {
+			return query, fmt.Errorf("invalid vaccinated: %w", err)
+		}
+		query.Vaccinated = &vaccinated
+	}
+
+	if v := values.Get("onMedication"); v != "" {
+		onMedication, err := strconv.ParseBool(v)
+		if err != nil {
+			return query, fmt.Errorf("invalid onMedication: %w", err)
+		}
+		query.OnMedication = &onMedication
+	}
+
+	query.Attributes = parseAttributeFilters(values)
+
+	if v := values.Get("limit"); v != "" {
+		limit, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || limit < 0 {
+			return query, fmt.Errorf("invalid limit: %q", v)
+		}
+		query.Limit = limit
+	}
+
+	if v := values.Get("offset"); v != "" {
+		offset, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || offset < 0 {
+			return query, fmt.Errorf("invalid offset: %q", v)
+		}
+		query.Offset = offset
+	}
+
+	return query, nil
+}
+
+// parseAttributeFilters picks the attr.{key}, attr.{key}.min and
+// attr.{key}.max parameters out of values. Their values are checked once
+// the field definitions are known.
+func parseAttributeFilters(values url.Values) []domain.AttributeFilter {
+	var names []string
+	for name := range values {
+		if strings.HasPrefix(name, "attr.") {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var filters []domain.AttributeFilter
+
+	for _, name := range names {
+		filter := domain.AttributeFilter{Key: strings.TrimPrefix(name, "attr."), Op: domain.AttributeIn}
+
+		if i := strings.LastIndex(filter.Key, "."); i >= 0 {
+			switch op := domain.AttributeOp(filter.Key[i+1:]); op {
+			case domain.AttributeMin, domain.AttributeMax:
+				filter.Key, filter.Op = filter.Key[:i], op
+			}
+		}
+
+		if filter.Op != domain.AttributeIn {
+			if v := values.Get(name); v != "" {
+				filter.Raw = []string{v}
+			}
+		} else {
+			for _, v := range values[name] {
+				for _, part := range strings.Split(v, ",") {
+					if part != "" {
+						filter.Raw = append(filter.Raw, part)
+					}
+				}
+			}
+		}
+
+		if len(filter.Raw) > 0 {
+			filters = append(filters, filter)
+		}
+	}
+
+	return filters
+}
+
+// bornBefore returns the birth date of an animal that is exactly age old
+// at now. Ages are written as a count and unit: "10d", "6w", "3m" or "2y".
+func bornBefore(now time.Time, age string) (time.Time, error) {
+	n := len(age)
+	if n < 2 {
+		return time.Time{}, fmt.Errorf("invalid age: %q", age)
+	}
+
+	count, err := strconv.Atoi(age[:n-1])
+	if err != nil || count < 0 {
+		return time.Time{}, fmt.Errorf("invalid age: %q", age)
+	}
+
+	switch age[n-1] {
+	case 'd':
+		return now.AddDate(0, 0, -count), nil
+	case 'w':
+		return now.AddDate(0, 0, -7*count), nil
+	case 'm':
+		return now.AddDate(0, -count, 0), nil
+	case 'y':
+		return now.AddDate(-count, 0, 0), nil
+	}
+
+	return time.Time{}, fmt.Errorf("invalid age: %q", age)
+}
+
+// parseInts accepts both repeated (?type=1&type=2) and comma separated
+// (?type=1,2) parameters.
+func parseInts(values url.Values, key string) ([]int, error) {
+	var result []int
+
+	for _, v := range values[key] {
+		for _, part := range strings.Split(v, ",") {
+			if part == "" {
+				continue
+			}
+
+			n, err := strconv.Atoi(part)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s: %q", key, part)
+			}
+			result = append(result, n)
+		}
+	}
+
+	return result, nil
+}
+
+func queryErrorStatus(err error) int {
+	if errors.Is(err, domain.ErrInvalidSort) || errors.Is(err, domain.ErrInvalidCursor) || errors.Is(err, domain.ErrInvalidSex) || errors.Is(err, domain.ErrInvalidStatus) {
+		return http.StatusBadRequest
+	}
+
+	if errors.Is(err, domain.ErrUnknownAttribute) || errors.Is(err, domain.ErrInvalidAttribute) || errors.Is(err, domain.ErrAttributeRange) {
+		return http.StatusBadRequest
+	}
+
+	return http.StatusInternalServerError
+}
+
+// parseWithin accepts day and week suffixes ("30d", "2w") on top of the
+// units understood by time.ParseDuration.
+func parseWithin(v string) (time.Duration, error) {
+	if n := len(v); n > 1 && (v[n-1] == 'd' || v[n-1] == 'w') {
+		count, err := strconv.Atoi(v[:n-1])
+		if err != nil || count < 0 {
+			return 0, fmt.Errorf("invalid duration: %q", v)
+		}
+
+		day := 24 * time.Hour
+		if v[n-1] == 'w' {
+			return time.Duration(count) * 7 * day, nil
+		}
+		return time.Duration(count) * day, nil
+	}
+
+	d, err := time.ParseDuration(v)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid duration: %q", v)
+	}
+
+	return d, nil
+}
+
+// parseDate accepts RFC 3339 timestamps as well as plain dates.
+func parseDate(v string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, nil
+	}
+
+	t, err := time.Parse("2006-01-02", v)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date: %q", v)
+	}
+
+	return t, nil
+}
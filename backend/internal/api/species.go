@@ -0,0 +1,265 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dspeirs7/animals/internal/domain"
+	"go.uber.org/zap"
+)
+
+// getTypes lists the animal type registry. Types that aren't enabled are
+// left out unless ?all=true.
+func (a *api) getTypes(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	switch r.Method {
+	case http.MethodGet:
+		var all bool
+		if v := r.URL.Query().Get("all"); v != "" {
+			var err error
+			if all, err = strconv.ParseBool(v); err != nil {
+				a.errorResponse(w, r, http.StatusBadRequest, err)
+				return
+			}
+		}
+
+		types, err := a.speciesRepo.List(ctx, all)
+		if err != nil {
+			a.errorResponse(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		a.jsonResponse(w, r, http.StatusOK, types)
+	case http.MethodPost:
+		species := domain.Species{Enabled: true}
+		if err := json.NewDecoder(r.Body).Decode(&species); err != nil {
+			a.errorResponse(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		if err := a.validateSpecies(ctx, &species); err != nil {
+			a.errorResponse(w, r, speciesErrorStatus(err), err)
+			return
+		}
+
+		result, err := a.speciesRepo.Insert(ctx, species)
+		if err != nil {
+			a.errorResponse(w, r, speciesErrorStatus(err), err)
+			return
+		}
+
+		a.jsonResponse(w, r, http.StatusCreated, result)
+	case http.MethodOptions:
+		w.Header().Set("Allow", "GET, POST, OPTIONS")
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.Header().Set("Allow", "GET, POST, OPTIONS")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleType serves /api/type/{slug}. A type's id never changes; a type
+// still used by animals or breeds can't be deleted.
+func (a *api) handleType(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	existing, err := a.speciesRepo.GetBySlug(ctx, path.Base(r.URL.Path))
+	if err != nil {
+		a.errorResponse(w, r, speciesErrorStatus(err), err)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		a.jsonResponse(w, r, http.StatusOK, existing)
+	case http.MethodPut:
+		species := domain.Species{Enabled: existing.Enabled}
+		if err := json.NewDecoder(r.Body).Decode(&species); err != nil {
+			a.errorResponse(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		species.Id = existing.Id
+		if err := a.validateSpecies(ctx, &species); err != nil {
+			a.errorResponse(w, r, speciesErrorStatus(err), err)
+			return
+		}
+
+		if err := a.speciesRepo.Update(ctx, existing.Id, species); err != nil {
+			a.errorResponse(w, r, speciesErrorStatus(err), err)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	case http.MethodDelete:
+		used, err := a.animalsExist(ctx, domain.AnimalQuery{Types: []domain.AnimalType{existing.Id}})
+		if err != nil {
+			a.errorResponse(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		breeds, err := a.breedRepo.List(ctx, existing.Id)
+		if err != nil {
+			a.errorResponse(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		if used || len(breeds) > 0 {
+			a.errorResponse(w, r, http.StatusConflict, domain.ErrSpeciesInUse)
+			return
+		}
+
+		if err := a.speciesRepo.Delete(ctx, existing.Id); err != nil {
+			a.errorResponse(w, r, speciesErrorStatus(err), err)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	case http.MethodOptions:
+		w.Header().Set("Allow", "GET, PUT, DELETE, OPTIONS")
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.Header().Set("Allow", "GET, PUT, DELETE, OPTIONS")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// getTypeAnimals serves /api/types/{slug}/animals, the generic form of
+// /api/cats and friends.
+func (a *api) getTypeAnimals(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(parts) != 4 || parts[3] != "animals" {
+		http.NotFound(w, r)
+		return
+	}
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	species, err := a.speciesRepo.GetBySlug(ctx, parts[2])
+	if err != nil {
+		a.errorResponse(w, r, speciesErrorStatus(err), err)
+		return
+	}
+
+	a.animalsOfType(w, r, species.Id)
+}
+
+func (a *api) validateSpecies(ctx context.Context, species *domain.Species) error {
+	if err := species.Validate(); err != nil {
+		return err
+	}
+
+	if species.ProtocolId == nil {
+		return nil
+	}
+
+	protocol, err := a.protocolRepo.GetById(ctx, species.ProtocolId.Hex())
+	if err != nil {
+		if errors.Is(err, domain.ErrProtocolNotFound) {
+			return domain.ErrSpeciesProtocol
+		}
+		return err
+	}
+
+	// Protocols are only saved for registered types, so a new type can't
+	// start out with one.
+	if protocol.Type != species.Id {
+		return domain.ErrSpeciesProtocol
+	}
+
+	return nil
+}
+
+// validateType checks an animal type is in the registry. Types that aren't
+// enabled only pass for animals that already exist.
+func (a *api) validateType(ctx context.Context, animalType domain.AnimalType, newAnimal bool) (*domain.Species, error) {
+	species, err := a.speciesRepo.GetById(ctx, animalType)
+	if err != nil {
+		if errors.Is(err, domain.ErrSpeciesNotFound) {
+			return nil, domain.ErrUnknownType
+		}
+		return nil, err
+	}
+
+	if newAnimal && !species.Enabled {
+		return nil, domain.ErrSpeciesDisabled
+	}
+
+	return species, nil
+}
+
+// withDefaultProtocol gives a new animal the pending first dose of its
+// type's default vaccination protocol.
+func (a *api) withDefaultProtocol(ctx context.Context, species *domain.Species, animal *domain.Animal) {
+	if species.ProtocolId == nil {
+		return
+	}
+
+	protocol, err := a.protocolRepo.GetById(ctx, species.ProtocolId.Hex())
+	if err != nil {
+		a.logger.Warn("error loading default protocol", zap.String("type", species.Slug), zap.Error(err))
+		return
+	}
+
+	if !protocol.Applies(animal) {
+		return
+	}
+
+	if pending, ok := protocol.Backfill(animal, time.Now()); ok {
+		animal.Vaccinations = append(animal.Vaccinations, pending)
+	}
+}
+
+// typeNames maps registry ids to names. It's empty if the registry can't be
+// read, leaving callers to fall back on AnimalType.String.
+func (a *api) typeNames(ctx context.Context) map[domain.AnimalType]string {
+	names := make(map[domain.AnimalType]string)
+
+	types, err := a.speciesRepo.List(ctx, true)
+	if err != nil {
+		a.logger.Warn("error listing animal types", zap.Error(err))
+		return names
+	}
+
+	for _, t := range types {
+		names[t.Id] = t.Name
+	}
+
+	return names
+}
+
+// speciesName looks t up in names from typeNames, falling back to the name
+// built into AnimalType.
+func speciesName(names map[domain.AnimalType]string, t domain.AnimalType) string {
+	if name := names[t]; name != "" {
+		return name
+	}
+	return t.String()
+}
+
+func speciesErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrSpeciesNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrSpeciesExists),
+		errors.Is(err, domain.ErrSpeciesInUse):
+		return http.StatusConflict
+	case errors.Is(err, domain.ErrMissingSpecies),
+		errors.Is(err, domain.ErrInvalidSlug),
+		errors.Is(err, domain.ErrUnknownType),
+		errors.Is(err, domain.ErrSpeciesDisabled),
+		errors.Is(err, domain.ErrSpeciesProtocol):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,111 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/dspeirs7/animals/internal/domain"
+	"github.com/dspeirs7/animals/internal/middleware"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type statusRequest struct {
+	Status    domain.Status       `json:"status"`
+	ContactId *primitive.ObjectID `json:"contactId"`
+	Note      string              `json:"note"`
+}
+
+// animalStatus serves /api/animal/{id}/status: GET is the animal's status
+// history, POST moves it to a new status.
+func (a *api) animalStatus(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	animal := r.Context().Value("animal").(*domain.Animal)
+	if animal.Id.IsZero() {
+		a.errorResponse(w, r, http.StatusNotFound, domain.ErrAnimalNotFound)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		history := animal.StatusHistory
+		if history == nil {
+			history = []domain.StatusChange{}
+		}
+
+		a.jsonResponse(w, r, http.StatusOK, history)
+	case http.MethodPost:
+		var request statusRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			a.errorResponse(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		change := domain.StatusChange{
+			Status:    request.Status,
+			At:        time.Now(),
+			ContactId: request.ContactId,
+			Note:      request.Note,
+		}
+
+		if session, ok := middleware.SessionFromContext(ctx); ok {
+			change.By = session.Username
+		}
+
+		if err := animal.ChangeStatus(&change); err != nil {
+			a.errorResponse(w, r, statusErrorStatus(err), err)
+			return
+		}
+
+		if err := a.checkContact(ctx, change.ContactId); err != nil {
+			a.errorResponse(w, r, statusErrorStatus(err), err)
+			return
+		}
+
+		if err := a.animalRepo.SetStatus(ctx, animal.Id, change); err != nil {
+			a.errorResponse(w, r, statusErrorStatus(err), err)
+			return
+		}
+
+		a.jsonResponse(w, r, http.StatusOK, change)
+	case http.MethodOptions:
+		w.Header().Set("Allow", "GET, POST, OPTIONS")
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.Header().Set("Allow", "GET, POST, OPTIONS")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// startStatus opens a new animal's status history.
+func (a *api) startStatus(ctx context.Context, animal *domain.Animal) error {
+	var by string
+	if session, ok := middleware.SessionFromContext(ctx); ok {
+		by = session.Username
+	}
+
+	if err := animal.StartStatus(time.Now(), by); err != nil {
+		return err
+	}
+
+	return a.checkContact(ctx, animal.ContactId)
+}
+
+// statusErrorStatus maps status change errors. An unknown contact is a bad
+// request here rather than a missing resource.
+func statusErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrStatusChanged):
+		return http.StatusConflict
+	case errors.Is(err, domain.ErrInvalidStatus),
+		errors.Is(err, domain.ErrStatusTransition),
+		errors.Is(err, domain.ErrContactRequired),
+		errors.Is(err, domain.ErrContactNotFound):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,183 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/dspeirs7/animals/internal/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+)
+
+const defaultTrashPurgeEvery = 24 * time.Hour
+
+// getTrash lists deleted animals waiting to be purged. It takes the same
+// parameters as /api/animals and pages the same way.
+func (a *api) getTrash(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		ctx, cancel := context.WithCancel(r.Context())
+		defer cancel()
+
+		query, err := parseAnimalQuery(r.URL.Query())
+		if err != nil {
+			a.errorResponse(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		query.Trashed = true
+
+		page, err := a.findAnimals(ctx, query)
+		if err != nil {
+			a.errorResponse(w, r, queryErrorStatus(err), err)
+			return
+		}
+
+		a.writeAnimalPage(w, r, page)
+	case http.MethodOptions:
+		w.Header().Set("Allow", "GET, OPTIONS")
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.Header().Set("Allow", "GET, OPTIONS")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// restoreAnimal serves POST /api/trash/{id}/restore, taking an animal back
+// out of the trash.
+func (a *api) restoreAnimal(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(parts) != 4 || parts[3] != "restore" {
+		http.NotFound(w, r)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPost:
+		ctx, cancel := context.WithCancel(r.Context())
+		defer cancel()
+
+		id, err := primitive.ObjectIDFromHex(parts[2])
+		if err != nil {
+			a.errorResponse(w, r, http.StatusNotFound, domain.ErrNotInTrash)
+			return
+		}
+
+		if err := a.animalRepo.Restore(ctx, id); err != nil {
+			a.errorResponse(w, r, trashErrorStatus(err), err)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	case http.MethodOptions:
+		w.Header().Set("Allow", "POST, OPTIONS")
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.Header().Set("Allow", "POST, OPTIONS")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// trashRetention is how long deleted animals are kept before being purged,
+// from TRASH_RETENTION.
+func (a *api) trashRetention() (time.Duration, error) {
+	if v := os.Getenv("TRASH_RETENTION"); v != "" {
+		return parseWithin(v)
+	}
+	return domain.DefaultTrashRetention, nil
+}
+
+// purgeTrash permanently deletes animals that have been in the trash
+// longer than retention, along with their images and records.
+func (a *api) purgeTrash(ctx context.Context, retention time.Duration) (int, error) {
+	animals, err := a.animalRepo.TrashedBefore(ctx, time.Now().Add(-retention))
+	if err != nil {
+		return 0, err
+	}
+
+	purged := 0
+
+	for _, animal := range animals {
+		if err := ctx.Err(); err != nil {
+			return purged, err
+		}
+
+		if err := a.purgeAnimal(ctx, animal); err != nil {
+			return purged, err
+		}
+		purged++
+	}
+
+	return purged, nil
+}
+
+// purgeAnimal removes an animal for good. Its children and litters are
+// unlinked first, so a failure leaves it in the trash for the next run;
+// leftovers after it is deleted are only logged.
+func (a *api) purgeAnimal(ctx context.Context, animal *domain.Animal) error {
+	id := animal.Id.Hex()
+
+	if err := a.animalRepo.RemoveParent(ctx, animal.Id); err != nil {
+		return err
+	}
+
+	if err := a.litterRepo.RemoveParent(ctx, animal.Id); err != nil {
+		return err
+	}
+
+	if err := a.animalRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	a.deleteAnimalImages(ctx, animal)
+
+	if err := a.medicalRepo.DeleteForAnimal(ctx, animal.Id); err != nil {
+		a.logger.Warn("error deleting medical records", zap.String("animal", id), zap.Error(err))
+	}
+
+	if err := a.doseRepo.DeleteForAnimal(ctx, animal.Id); err != nil {
+		a.logger.Warn("error deleting doses", zap.String("animal", id), zap.Error(err))
+	}
+
+	if err := a.eggRepo.DeleteForAnimal(ctx, animal.Id); err != nil {
+		a.logger.Warn("error deleting egg logs", zap.String("animal", id), zap.Error(err))
+	}
+
+	if err := a.feedingRepo.RemoveAnimal(ctx, animal.Id); err != nil {
+		a.logger.Warn("error removing animal from feeding plans", zap.String("animal", id), zap.Error(err))
+	}
+
+	return nil
+}
+
+// animalsExist reports whether any animal, in the trash or not, matches
+// query. Catalog entries they point at have to stay for restores.
+func (a *api) animalsExist(ctx context.Context, query domain.AnimalQuery) (bool, error) {
+	query.Limit = 1
+
+	for _, trashed := range []bool{false, true} {
+		query.Trashed = trashed
+
+		page, err := a.animalRepo.Find(ctx, query)
+		if err != nil {
+			return false, err
+		}
+
+		if page.Total > 0 {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func trashErrorStatus(err error) int {
+	if errors.Is(err, domain.ErrNotInTrash) || errors.Is(err, domain.ErrAnimalNotFound) {
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
@@ -3,13 +3,34 @@ package api
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
+	"path"
 	"time"
 
 	"github.com/dspeirs7/animals/internal/domain"
-	"golang.org/x/crypto/bcrypt"
+	"github.com/dspeirs7/animals/internal/middleware"
 )
 
+type credentials struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+type passwordChange struct {
+	CurrentPassword string `json:"currentPassword"`
+	NewPassword     string `json:"newPassword"`
+}
+
+type userRequest struct {
+	Username string       `json:"username"`
+	Password string       `json:"password"`
+	Role     *domain.Role `json:"role,omitempty"`
+	Disabled *bool        `json:"disabled,omitempty"`
+}
+
+var errInvalidCredentials = errors.New("invalid username or password")
+
 func (a *api) login(w http.ResponseWriter, r *http.Request) {
 	switch r.Method {
 	case http.MethodPost:
@@ -17,30 +38,36 @@ func (a *api) login(w http.ResponseWriter, r *http.Request) {
 		defer cancel()
 
 		decoder := json.NewDecoder(r.Body)
-		var user domain.User
+		var creds credentials
 
-		if err := decoder.Decode(&user); err != nil {
-			a.errorResponse(w, r, http.StatusInternalServerError, err)
+		if err := decoder.Decode(&creds); err != nil {
+			a.errorResponse(w, r, http.StatusBadRequest, err)
 			return
 		}
 
-		admin, err := a.userRepo.GetUser(ctx, "admin")
-		if err != nil {
+		user, err := a.userRepo.GetUser(ctx, creds.Username)
+		if errors.Is(err, domain.ErrUserNotFound) {
+			a.errorResponse(w, r, http.StatusUnauthorized, errInvalidCredentials)
+			return
+		} else if err != nil {
 			a.errorResponse(w, r, http.StatusInternalServerError, err)
 			return
 		}
 
-		if err := bcrypt.CompareHashAndPassword([]byte(admin.Password), []byte(user.Password)); err != nil {
-			a.errorResponse(w, r, http.StatusUnauthorized, err)
+		if user.Disabled || user.CheckPassword(creds.Password) != nil {
+			a.errorResponse(w, r, http.StatusUnauthorized, errInvalidCredentials)
 			return
 		}
 
-		expiresAt := time.Now().Add(3600 * time.Second)
-		sessionId := domain.SetSession(domain.Session{Username: admin.Username, Expiry: expiresAt})
+		expiresAt := time.Now().Add(domain.SessionDuration)
+		sessionId, err := a.sessions.Create(ctx, domain.Session{Username: user.Username, Role: user.Role, Expiry: expiresAt})
+		if err != nil {
+			a.errorResponse(w, r, http.StatusInternalServerError, err)
+			return
+		}
 
 		http.SetCookie(w, &http.Cookie{Name: "session_token", Value: sessionId, Expires: expiresAt, Path: "/", SameSite: http.SameSiteLaxMode})
-		w.WriteHeader(http.StatusOK)
-		json.NewEncoder(w).Encode(map[string]string{"sessionId": sessionId})
+		a.jsonResponse(w, r, http.StatusOK, map[string]string{"sessionId": sessionId, "username": user.Username, "role": string(user.Role)})
 	case http.MethodOptions:
 		w.Header().Set("Allow", "POST, OPTIONS")
 		w.WriteHeader(http.StatusNoContent)
@@ -61,8 +88,12 @@ func (a *api) logout(w http.ResponseWriter, r *http.Request) {
 		}
 
 		sessionId := cookie.Value
-		domain.RemoveSession(sessionId)
+		if err := a.sessions.Delete(r.Context(), sessionId); err != nil {
+			a.errorResponse(w, r, http.StatusInternalServerError, err)
+			return
+		}
 
+		http.SetCookie(w, &http.Cookie{Name: "session_token", Value: "", MaxAge: -1, Path: "/", SameSite: http.SameSiteLaxMode})
 		w.WriteHeader(http.StatusOK)
 		json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
 	case http.MethodOptions:
@@ -73,3 +104,244 @@ func (a *api) logout(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
 	}
 }
+
+func (a *api) changePassword(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		ctx, cancel := context.WithCancel(r.Context())
+		defer cancel()
+
+		session, _ := middleware.SessionFromContext(ctx)
+
+		var change passwordChange
+		if err := json.NewDecoder(r.Body).Decode(&change); err != nil {
+			a.errorResponse(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		user, err := a.userRepo.GetUser(ctx, session.Username)
+		if err != nil {
+			a.errorResponse(w, r, userErrorStatus(err), err)
+			return
+		}
+
+		if err := user.CheckPassword(change.CurrentPassword); err != nil {
+			a.errorResponse(w, r, http.StatusUnauthorized, errInvalidCredentials)
+			return
+		}
+
+		if err := a.setPassword(ctx, user.Username, change.NewPassword); err != nil {
+			a.errorResponse(w, r, userErrorStatus(err), err)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	case http.MethodOptions:
+		w.Header().Set("Allow", "POST, OPTIONS")
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.Header().Set("Allow", "POST, OPTIONS")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (a *api) getUsers(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	switch r.Method {
+	case http.MethodGet:
+		users, err := a.userRepo.List(ctx)
+		if err != nil {
+			a.errorResponse(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		a.jsonResponse(w, r, http.StatusOK, users)
+	case http.MethodPost:
+		var req userRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			a.errorResponse(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		role := domain.RoleViewer
+		if req.Role != nil {
+			role = *req.Role
+		}
+
+		if req.Username == "" {
+			a.errorResponse(w, r, http.StatusBadRequest, errors.New("username is required"))
+			return
+		}
+
+		if !role.Valid() {
+			a.errorResponse(w, r, http.StatusBadRequest, domain.ErrInvalidRole)
+			return
+		}
+
+		if err := domain.ValidatePassword(req.Password); err != nil {
+			a.errorResponse(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		hashed, err := domain.HashPassword(req.Password)
+		if err != nil {
+			a.errorResponse(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		user, err := a.userRepo.Insert(ctx, domain.User{Username: req.Username, Password: hashed, Role: role})
+		if err != nil {
+			a.errorResponse(w, r, userErrorStatus(err), err)
+			return
+		}
+
+		a.jsonResponse(w, r, http.StatusCreated, user)
+	case http.MethodOptions:
+		w.Header().Set("Allow", "GET, POST, OPTIONS")
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.Header().Set("Allow", "GET, POST, OPTIONS")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (a *api) handleUser(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	username := path.Base(r.URL.Path)
+
+	switch r.Method {
+	case http.MethodGet:
+		user, err := a.userRepo.GetUser(ctx, username)
+		if err != nil {
+			a.errorResponse(w, r, userErrorStatus(err), err)
+			return
+		}
+
+		a.jsonResponse(w, r, http.StatusOK, user)
+	case http.MethodPut:
+		var req userRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			a.errorResponse(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		if req.Role != nil && !req.Role.Valid() {
+			a.errorResponse(w, r, http.StatusBadRequest, domain.ErrInvalidRole)
+			return
+		}
+
+		if req.Password != "" {
+			if err := domain.ValidatePassword(req.Password); err != nil {
+				a.errorResponse(w, r, userErrorStatus(err), err)
+				return
+			}
+		}
+
+		demoted := req.Role != nil && *req.Role != domain.RoleAdmin
+		disabled := req.Disabled != nil && *req.Disabled
+		if demoted || disabled {
+			if err := a.checkLastAdmin(ctx, username); err != nil {
+				a.errorResponse(w, r, userErrorStatus(err), err)
+				return
+			}
+		}
+
+		if err := a.userRepo.Update(ctx, username, domain.UserUpdate{Role: req.Role, Disabled: req.Disabled}); err != nil {
+			a.errorResponse(w, r, userErrorStatus(err), err)
+			return
+		}
+
+		if req.Password != "" {
+			if err := a.setPassword(ctx, username, req.Password); err != nil {
+				a.errorResponse(w, r, userErrorStatus(err), err)
+				return
+			}
+		} else if req.Role != nil || req.Disabled != nil {
+			if err := a.sessions.DeleteForUser(ctx, username); err != nil {
+				a.errorResponse(w, r, http.StatusInternalServerError, err)
+				return
+			}
+		}
+
+		w.WriteHeader(http.StatusOK)
+	case http.MethodDelete:
+		if err := a.checkLastAdmin(ctx, username); err != nil {
+			a.errorResponse(w, r, userErrorStatus(err), err)
+			return
+		}
+
+		if err := a.userRepo.Delete(ctx, username); err != nil {
+			a.errorResponse(w, r, userErrorStatus(err), err)
+			return
+		}
+
+		if err := a.sessions.DeleteForUser(ctx, username); err != nil {
+			a.errorResponse(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	case http.MethodOptions:
+		w.Header().Set("Allow", "GET, PUT, DELETE, OPTIONS")
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.Header().Set("Allow", "GET, PUT, DELETE, OPTIONS")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// setPassword also ends the user's existing sessions.
+func (a *api) setPassword(ctx context.Context, username string, password string) error {
+	if err := domain.ValidatePassword(password); err != nil {
+		return err
+	}
+
+	hashed, err := domain.HashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	if err := a.userRepo.SetPassword(ctx, username, hashed); err != nil {
+		return err
+	}
+
+	return a.sessions.DeleteForUser(ctx, username)
+}
+
+func (a *api) checkLastAdmin(ctx context.Context, username string) error {
+	user, err := a.userRepo.GetUser(ctx, username)
+	if err != nil {
+		return err
+	}
+
+	if user.Role != domain.RoleAdmin || user.Disabled {
+		return nil
+	}
+
+	admins, err := a.userRepo.CountActiveAdmins(ctx)
+	if err != nil {
+		return err
+	}
+
+	if admins <= 1 {
+		return domain.ErrLastAdmin
+	}
+
+	return nil
+}
+
+func userErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrUserNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrUserExists), errors.Is(err, domain.ErrLastAdmin):
+		return http.StatusConflict
+	case errors.Is(err, domain.ErrInvalidRole), errors.Is(err, domain.ErrPasswordTooWeak):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,71 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/dspeirs7/animals/internal/domain"
+)
+
+const defaultDueWithin = 30 * 24 * time.Hour
+
+func (a *api) getVaccinationsDue(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		within := defaultDueWithin
+		if v := r.URL.Query().Get("within"); v != "" {
+			var err error
+			if within, err = parseWithin(v); err != nil {
+				a.errorResponse(w, r, http.StatusBadRequest, err)
+				return
+			}
+		}
+
+		now := time.Now()
+		a.vaccinationsDue(w, r, now, now.Add(within))
+	case http.MethodOptions:
+		w.Header().Set("Allow", "GET, OPTIONS")
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.Header().Set("Allow", "GET, OPTIONS")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (a *api) getVaccinationsOverdue(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		a.vaccinationsDue(w, r, time.Time{}, time.Now())
+	case http.MethodOptions:
+		w.Header().Set("Allow", "GET, OPTIONS")
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.Header().Set("Allow", "GET, OPTIONS")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (a *api) vaccinationsDue(w http.ResponseWriter, r *http.Request, from time.Time, to time.Time) {
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	types, err := parseInts(r.URL.Query(), "type")
+	if err != nil {
+		a.errorResponse(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	query := domain.VaccinationDueQuery{From: from, To: to}
+	for _, t := range types {
+		query.Types = append(query.Types, domain.AnimalType(t))
+	}
+
+	results, err := a.animalRepo.VaccinationsDue(ctx, query)
+	if err != nil {
+		a.errorResponse(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	a.jsonResponse(w, r, http.StatusOK, results)
+}
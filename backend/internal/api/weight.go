@@ -0,0 +1,118 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"path"
+	"time"
+
+	"github.com/dspeirs7/animals/internal/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func (a *api) addWeights(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		ctx, cancel := context.WithCancel(r.Context())
+		defer cancel()
+
+		id := path.Base(r.URL.Path)
+
+		decoder := json.NewDecoder(r.Body)
+
+		var weights []domain.Weight
+
+		if err := decoder.Decode(&weights); err != nil {
+			a.errorResponse(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		for i := range weights {
+			if err := weights[i].Validate(); err != nil {
+				a.errorResponse(w, r, http.StatusBadRequest, err)
+				return
+			}
+
+			weights[i].Id = primitive.NewObjectID()
+			if weights[i].Date == 0 {
+				weights[i].Date = primitive.NewDateTimeFromTime(time.Now())
+			}
+		}
+
+		if err := a.animalRepo.AddWeights(ctx, id, weights); err != nil {
+			a.errorResponse(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		a.jsonResponse(w, r, http.StatusOK, weights)
+	case http.MethodOptions:
+		w.Header().Set("Allow", "POST, OPTIONS")
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.Header().Set("Allow", "POST, OPTIONS")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (a *api) deleteWeight(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		ctx, cancel := context.WithCancel(r.Context())
+		defer cancel()
+
+		id := path.Base(r.URL.Path)
+
+		decoder := json.NewDecoder(r.Body)
+
+		var weight domain.Weight
+
+		if err := decoder.Decode(&weight); err != nil {
+			a.errorResponse(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		if err := a.animalRepo.DeleteWeight(ctx, id, weight.Id); err != nil {
+			a.errorResponse(w, r, http.StatusInternalServerError, err)
+			return
+		}
+	case http.MethodOptions:
+		w.Header().Set("Allow", "POST, OPTIONS")
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.Header().Set("Allow", "POST, OPTIONS")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// getWeights serves /api/animal/{id}/weights?unit=lb&interval=week.
+func (a *api) getWeights(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		animal := r.Context().Value("animal").(*domain.Animal)
+
+		unit := domain.WeightUnit(r.URL.Query().Get("unit"))
+		if unit == "" {
+			unit = domain.Kilograms
+		}
+
+		if !unit.Valid() {
+			a.errorResponse(w, r, http.StatusBadRequest, domain.ErrInvalidWeightUnit)
+			return
+		}
+
+		interval := domain.WeightInterval(r.URL.Query().Get("interval"))
+		if !interval.Valid() {
+			a.errorResponse(w, r, http.StatusBadRequest, domain.ErrInvalidInterval)
+			return
+		}
+
+		a.jsonResponse(w, r, http.StatusOK, domain.WeightSeries(animal.Weights, unit, interval, a.location))
+	case http.MethodOptions:
+		w.Header().Set("Allow", "GET, OPTIONS")
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.Header().Set("Allow", "GET, OPTIONS")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
@@ -0,0 +1,128 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrInvalidSex      = errors.New("sex must be male, female or unknown")
+	ErrFutureBirthDate = errors.New("birth date cannot be in the future")
+)
+
+type Sex string
+
+const (
+	SexUnknown Sex = "unknown"
+	SexMale    Sex = "male"
+	SexFemale  Sex = "female"
+)
+
+func (s Sex) Valid() bool {
+	switch s {
+	case "", SexUnknown, SexMale, SexFemale:
+		return true
+	}
+	return false
+}
+
+type Age struct {
+	Years       int    `json:"years"`
+	Months      int    `json:"months"`
+	Days        int    `json:"days"`
+	TotalDays   int    `json:"totalDays"`
+	Approximate bool   `json:"approximate,omitempty"`
+	Text        string `json:"text"`
+}
+
+// AgeAt returns the animal's age on the given day, or nil when its birth
+// date isn't known.
+func (a *Animal) AgeAt(t time.Time) *Age {
+	if a.BirthDate == 0 {
+		return nil
+	}
+
+	born := a.BirthDate.Time().In(t.Location())
+	if born.After(t) {
+		return nil
+	}
+
+	years := t.Year() - born.Year()
+	months := int(t.Month()) - int(born.Month())
+	// A month is complete on its last day even when the animal was born on
+	// a later day of the month.
+	if endOfMonth := time.Date(t.Year(), t.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day(); t.Day() < born.Day() && t.Day() < endOfMonth {
+		months--
+	}
+
+	if months < 0 {
+		years--
+		months += 12
+	}
+
+	// Count the days from the last monthly birthday, which falls on the
+	// month's last day when the month is too short.
+	month := time.Month(int(born.Month()) + months)
+	day := born.Day()
+	if last := time.Date(born.Year()+years, month+1, 0, 0, 0, 0, 0, time.UTC).Day(); day > last {
+		day = last
+	}
+	anniversary := time.Date(born.Year()+years, month, day, 0, 0, 0, 0, time.UTC)
+	days := int(time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC).Sub(anniversary).Hours() / 24)
+
+	age := &Age{
+		Years:       years,
+		Months:      months,
+		Days:        days,
+		TotalDays:   int(t.Sub(born).Hours() / 24),
+		Approximate: a.BirthDateApproximate,
+	}
+	age.Text = age.String()
+
+	return age
+}
+
+func (a *Age) String() string {
+	var text string
+
+	switch {
+	case a.Years > 0 && a.Months > 0:
+		text = fmt.Sprintf("%s %s", plural(a.Years, "year"), plural(a.Months, "month"))
+	case a.Years > 0:
+		text = plural(a.Years, "year")
+	case a.Months > 0:
+		text = plural(a.Months, "month")
+	case a.TotalDays >= 7:
+		text = plural(a.TotalDays/7, "week")
+	default:
+		text = plural(a.TotalDays, "day")
+	}
+
+	if a.Approximate {
+		return "about " + text
+	}
+
+	return text
+}
+
+func plural(n int, unit string) string {
+	if n == 1 {
+		return fmt.Sprintf("1 %s", unit)
+	}
+	return fmt.Sprintf("%d %ss", n, unit)
+}
+
+// MarshalJSON adds the computed age to the stored fields.
+func (a Animal) MarshalJSON() ([]byte, error) {
+	type animal Animal
+
+	return json.Marshal(struct {
+		animal
+		Age *Age `json:"age,omitempty"`
+	}{
+		animal: animal(a),
+		Age:    a.AgeAt(time.Now()),
+	})
+}
@@ -0,0 +1,71 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestAgeAt(t *testing.T) {
+	date := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name        string
+		born        time.Time
+		approximate bool
+		at          time.Time
+		want        *Age
+	}{
+		{"unknown birth date", time.Time{}, false, date(2024, 3, 1), nil},
+		{"born later", date(2024, 3, 2), false, date(2024, 3, 1), nil},
+		{"born today", date(2024, 3, 1), false, date(2024, 3, 1), &Age{Text: "0 days"}},
+		{"one day", date(2024, 2, 29), false, date(2024, 3, 1), &Age{Days: 1, TotalDays: 1, Text: "1 day"}},
+		{"weeks", date(2024, 2, 1), false, date(2024, 2, 20), &Age{Days: 19, TotalDays: 19, Text: "2 weeks"}},
+		{"borrows the previous month", date(2024, 1, 15), false, date(2024, 3, 1), &Age{Months: 1, Days: 15, TotalDays: 46, Text: "1 month"}},
+		{"born on a day the month lacks", date(2024, 1, 31), false, date(2024, 3, 1), &Age{Months: 1, Days: 1, TotalDays: 30, Text: "1 month"}},
+		{"monthly birthday on the last day", date(2024, 1, 31), false, date(2024, 2, 29), &Age{Months: 1, TotalDays: 29, Text: "1 month"}},
+		{"one year", date(2023, 3, 1), false, date(2024, 3, 1), &Age{Years: 1, TotalDays: 366, Text: "1 year"}},
+		{"years and months", date(2021, 6, 15), false, date(2024, 3, 1), &Age{Years: 2, Months: 8, Days: 15, TotalDays: 990, Text: "2 years 8 months"}},
+		{"leap day birthday", date(2020, 2, 29), false, date(2021, 2, 28), &Age{Years: 1, TotalDays: 365, Text: "1 year"}},
+		{"year end", date(2023, 11, 30), false, date(2024, 2, 15), &Age{Months: 2, Days: 16, TotalDays: 77, Text: "2 months"}},
+		{"approximate", date(2019, 3, 1), true, date(2024, 3, 1), &Age{Years: 5, TotalDays: 1827, Approximate: true, Text: "about 5 years"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			animal := Animal{BirthDateApproximate: tt.approximate}
+			if !tt.born.IsZero() {
+				animal.BirthDate = primitive.NewDateTimeFromTime(tt.born)
+			}
+
+			got := animal.AgeAt(tt.at)
+			if tt.want == nil {
+				if got != nil {
+					t.Fatalf("AgeAt = %+v, want nil", got)
+				}
+				return
+			}
+
+			// Age's String method would hide the fields.
+			type fields Age
+			if got == nil || *got != *tt.want {
+				t.Errorf("AgeAt = %+v, want %+v", (*fields)(got), fields(*tt.want))
+			}
+		})
+	}
+}
+
+func TestAgeAtUsesTheCallersZone(t *testing.T) {
+	// Born just before midnight UTC on the 1st, which is still the 1st in
+	// UTC but already the 2nd east of it.
+	animal := Animal{BirthDate: primitive.NewDateTimeFromTime(time.Date(2024, 1, 1, 23, 0, 0, 0, time.UTC))}
+	east := time.FixedZone("UTC+3", 3*60*60)
+
+	got := animal.AgeAt(time.Date(2024, 2, 2, 12, 0, 0, 0, east))
+	if got.Months != 1 || got.Days != 0 {
+		t.Errorf("AgeAt = %+v, want exactly one month", got)
+	}
+}
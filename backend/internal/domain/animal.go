@@ -2,20 +2,44 @@ package domain
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type Animal struct {
-	Id           primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
-	Name         string             `bson:"name,omitempty" json:"name,omitempty"`
-	Description  string             `bson:"description,omitempty" json:"description,omitempty"`
-	ImageUrl     string             `bson:"imageUrl,omitempty" json:"imageUrl,omitempty"`
-	Type         AnimalType         `bson:"type,omitempty" json:"type,omitempty"`
-	Breed        AnimalBreed        `bson:"breed,omitempty" json:"breed,omitempty"`
-	Vaccinations []Vaccination      `bson:"vaccinations,omitempty" json:"vaccinations,omitempty"`
+	Id                   primitive.ObjectID     `bson:"_id,omitempty" json:"id,omitempty"`
+	Name                 string                 `bson:"name,omitempty" json:"name,omitempty"`
+	Description          string                 `bson:"description,omitempty" json:"description,omitempty"`
+	ImageUrl             string                 `bson:"imageUrl,omitempty" json:"imageUrl,omitempty"`
+	Images               *ImageSet              `bson:"images,omitempty" json:"images,omitempty"`
+	Photos               []Photo                `bson:"photos,omitempty" json:"photos,omitempty"`
+	Type                 AnimalType             `bson:"type,omitempty" json:"type,omitempty"`
+	Breed                AnimalBreed            `bson:"breed,omitempty" json:"breed,omitempty"`
+	BirthDate            primitive.DateTime     `bson:"birthDate,omitempty" json:"birthDate,omitempty"`
+	BirthDateApproximate bool                   `bson:"birthDateApproximate,omitempty" json:"birthDateApproximate,omitempty"`
+	Sex                  Sex                    `bson:"sex,omitempty" json:"sex,omitempty"`
+	Altered              bool                   `bson:"altered,omitempty" json:"altered,omitempty"`
+	Vaccinations         []Vaccination          `bson:"vaccinations,omitempty" json:"vaccinations,omitempty"`
+	Weights              []Weight               `bson:"weights,omitempty" json:"weights,omitempty"`
+	SireId               *primitive.ObjectID    `bson:"sireId,omitempty" json:"sireId,omitempty"`
+	DamId                *primitive.ObjectID    `bson:"damId,omitempty" json:"damId,omitempty"`
+	ParentIds            []primitive.ObjectID   `bson:"parentIds,omitempty" json:"-"`
+	LitterId             *primitive.ObjectID    `bson:"litterId,omitempty" json:"litterId,omitempty"`
+	Attributes           map[string]interface{} `bson:"attributes,omitempty" json:"attributes,omitempty"`
+	Microchip            string                 `bson:"microchip,omitempty" json:"microchip,omitempty"`
+	LegBand              string                 `bson:"legBand,omitempty" json:"legBand,omitempty"`
+	CollarTag            string                 `bson:"collarTag,omitempty" json:"collarTag,omitempty"`
+	Status               Status                 `bson:"status,omitempty" json:"status,omitempty"`
+	StatusHistory        []StatusChange         `bson:"statusHistory,omitempty" json:"statusHistory,omitempty"`
+	ContactId            *primitive.ObjectID    `bson:"contactId,omitempty" json:"contactId,omitempty"`
+	DeletedAt            *time.Time             `bson:"deletedAt,omitempty" json:"deletedAt,omitempty"`
+	DeletedBy            string                 `bson:"deletedBy,omitempty" json:"deletedBy,omitempty"`
 }
 
+// AnimalType is the id of a Species in the type registry. The constants are
+// the types the registry is seeded with.
 type AnimalType int
 
 const (
@@ -24,6 +48,36 @@ const (
 	DogType     AnimalType = 3
 )
 
+// Validate also puts the animal's identifiers in their stored form.
+func (a *Animal) Validate() error {
+	if !a.Sex.Valid() {
+		return ErrInvalidSex
+	}
+
+	if a.BirthDate != 0 && a.BirthDate.Time().After(time.Now()) {
+		return ErrFutureBirthDate
+	}
+
+	if err := a.normalizeIdentifiers(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// String names the seeded types for when the registry isn't at hand.
+func (t AnimalType) String() string {
+	switch t {
+	case CatType:
+		return "Cat"
+	case ChickenType:
+		return "Chicken"
+	case DogType:
+		return "Dog"
+	}
+	return "Animal"
+}
+
 type AnimalBreed int
 
 type Vaccination struct {
@@ -32,15 +86,91 @@ type Vaccination struct {
 	DateNeeded primitive.DateTime `bson:"dateNeeded,omitempty" json:"dateNeeded,omitEmpty"`
 }
 
+type VaccinationDue struct {
+	AnimalId    primitive.ObjectID `bson:"animalId" json:"animalId"`
+	AnimalName  string             `bson:"animalName" json:"animalName"`
+	AnimalType  AnimalType         `bson:"animalType" json:"animalType"`
+	Vaccination Vaccination        `bson:"vaccination" json:"vaccination"`
+}
+
+// VaccinationDueQuery matches the latest record of each vaccination per
+// animal whose DateNeeded falls in [From, To). A zero From means no lower
+// bound.
+type VaccinationDueQuery struct {
+	From  time.Time
+	To    time.Time
+	Types []AnimalType
+}
+
+type AnimalQuery struct {
+	Types        []AnimalType
+	Breeds       []AnimalBreed
+	NamePrefix   string
+	Sexes        []Sex
+	Statuses     []Status
+	Altered      *bool
+	BornAfter    time.Time
+	BornBefore   time.Time
+	Vaccinated   *bool
+	OnMedication *bool // becomes Ids or ExcludeIds in the api
+	Ids          []primitive.ObjectID
+	ExcludeIds   []primitive.ObjectID
+	Sort         string
+	Limit        int64
+	Offset       int64
+	Cursor       string
+	Attributes   []AttributeFilter
+	Trashed      bool // lists the trash instead of the others
+}
+
+type AnimalPage struct {
+	Animals    []*Animal
+	Total      int64
+	NextCursor string
+}
+
+var (
+	ErrInvalidSort   = errors.New("invalid sort field")
+	ErrInvalidCursor = errors.New("invalid cursor")
+)
+
+const (
+	DefaultAnimalLimit int64 = 50
+	MaxAnimalLimit     int64 = 500
+)
+
 type AnimalRepository interface {
-	GetAllCats(ctx context.Context) ([]*Animal, error)
-	GetAllChickens(ctx context.Context) ([]*Animal, error)
-	GetAllDogs(ctx context.Context) ([]*Animal, error)
+	Find(ctx context.Context, query AnimalQuery) (*AnimalPage, error)
 	GetById(ctx context.Context, id string) (*Animal, error)
 	Insert(ctx context.Context, insert Animal) (*Animal, error)
 	Update(ctx context.Context, id string, update Animal) error
 	AddVaccinations(ctx context.Context, id string, vaccinations []Vaccination) error
 	DeleteVaccination(ctx context.Context, id string, vaccination Vaccination) error
+	AddWeights(ctx context.Context, id string, weights []Weight) error
+	DeleteWeight(ctx context.Context, id string, weightId primitive.ObjectID) error
+	VaccinationsDue(ctx context.Context, query VaccinationDueQuery) ([]*VaccinationDue, error)
 	Delete(ctx context.Context, id string) error
-	UpdateImageUrl(ctx context.Context, id string, url string) error
+	MaterializeLegacyPhoto(ctx context.Context, id string, photo Photo) error
+	AddPhoto(ctx context.Context, id string, photo Photo) error
+	UpdatePhoto(ctx context.Context, id string, photo Photo) error
+	SetPrimaryPhoto(ctx context.Context, id string, photoId primitive.ObjectID) error
+	RemovePhoto(ctx context.Context, id string, photoId primitive.ObjectID) error
+	ReorderPhotos(ctx context.Context, id string, ids []primitive.ObjectID) error
+	ImageReferences(ctx context.Context, url string) (int64, error)
+	ReferencedImageUrls(ctx context.Context) (map[string]bool, error)
+	Ancestors(ctx context.Context, id primitive.ObjectID, depth int) ([]*Animal, error)
+	Descendants(ctx context.Context, id primitive.ObjectID, depth int) ([]*Animal, error)
+	DescendantIds(ctx context.Context, id primitive.ObjectID) ([]primitive.ObjectID, error)
+	LitterMembers(ctx context.Context, litterId primitive.ObjectID, includeTrashed bool) ([]*Animal, error)
+	RemoveAttribute(ctx context.Context, animalType AnimalType, key string) error
+	CountMissingAttribute(ctx context.Context, animalType AnimalType, key string) (int64, error)
+	FindByIdentifier(ctx context.Context, id string) (*Animal, error)
+	SetStatus(ctx context.Context, id primitive.ObjectID, change StatusChange) error
+	ContactAnimals(ctx context.Context, contactId primitive.ObjectID) ([]*Animal, error)
+	Trash(ctx context.Context, id primitive.ObjectID, at time.Time, by string) error
+	Restore(ctx context.Context, id primitive.ObjectID) error
+	TrashedBefore(ctx context.Context, before time.Time) ([]*Animal, error)
+	SetLitterParents(ctx context.Context, litter *Litter) error
+	ClearLitter(ctx context.Context, litterId primitive.ObjectID) error
+	RemoveParent(ctx context.Context, parentId primitive.ObjectID) error
 }
@@ -0,0 +1,48 @@
+package domain
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	ErrBreedNotFound  = errors.New("breed not found")
+	ErrBreedExists    = errors.New("a breed with this id already exists")
+	ErrBreedInUse     = errors.New("breed is still used by animals")
+	ErrMissingBreed   = errors.New("breed needs a name and a type")
+	ErrUnknownBreed   = errors.New("unknown breed")
+	ErrBreedWrongType = errors.New("breed doesn't belong to the animal's type")
+)
+
+// Breed is an entry in the breed catalog. Ids are the AnimalBreed numbers
+// stored on animals.
+type Breed struct {
+	Id   AnimalBreed `bson:"_id" json:"id"`
+	Type AnimalType  `bson:"type" json:"type"`
+	Name string      `bson:"name" json:"name"`
+}
+
+func (b *Breed) Validate() error {
+	if b.Name == "" || b.Type == 0 {
+		return ErrMissingBreed
+	}
+	return nil
+}
+
+// DefaultBreeds seeds an empty catalog with the breeds the app started
+// out with.
+var DefaultBreeds = []Breed{
+	{Id: 1, Type: CatType, Name: "Russian Blue"},
+	{Id: 10, Type: ChickenType, Name: "Brahma"},
+	{Id: 11, Type: ChickenType, Name: "Buff Orpington"},
+	{Id: 20, Type: DogType, Name: "Mix"},
+	{Id: 21, Type: DogType, Name: "Fox Hound"},
+}
+
+type BreedRepository interface {
+	List(ctx context.Context, animalType AnimalType) ([]*Breed, error)
+	Get(ctx context.Context, id AnimalBreed) (*Breed, error)
+	Insert(ctx context.Context, breed Breed) (*Breed, error)
+	Update(ctx context.Context, id AnimalBreed, breed Breed) error
+	Delete(ctx context.Context, id AnimalBreed) error
+}
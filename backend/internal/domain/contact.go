@@ -0,0 +1,41 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	ErrContactNotFound = errors.New("contact not found")
+	ErrContactInUse    = errors.New("contact is linked to animals")
+	ErrMissingContact  = errors.New("contact needs a name")
+)
+
+// Contact is a person animals leave us with: an adopter, foster or buyer.
+type Contact struct {
+	Id      primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Name    string             `bson:"name" json:"name"`
+	Email   string             `bson:"email,omitempty" json:"email,omitempty"`
+	Phone   string             `bson:"phone,omitempty" json:"phone,omitempty"`
+	Address string             `bson:"address,omitempty" json:"address,omitempty"`
+	Notes   string             `bson:"notes,omitempty" json:"notes,omitempty"`
+	Animals []*Animal          `bson:"-" json:"animals,omitempty"`
+}
+
+func (c *Contact) Validate() error {
+	if strings.TrimSpace(c.Name) == "" {
+		return ErrMissingContact
+	}
+	return nil
+}
+
+type ContactRepository interface {
+	List(ctx context.Context, namePrefix string) ([]*Contact, error)
+	GetById(ctx context.Context, id string) (*Contact, error)
+	Insert(ctx context.Context, contact Contact) (*Contact, error)
+	Update(ctx context.Context, id string, contact Contact) error
+	Delete(ctx context.Context, id string) error
+}
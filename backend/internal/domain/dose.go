@@ -0,0 +1,225 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	ErrDoseNotFound     = errors.New("dose not found")
+	ErrDoseAdministered = errors.New("dose has already been administered")
+	ErrInvalidTimeOfDay = errors.New("times of day must be written as 15:04")
+)
+
+// MissedDoseAfter is how long after its due time a dose that hasn't been
+// given counts as missed.
+const MissedDoseAfter = 2 * time.Hour
+
+// firstDoseHour is when the first dose of the day falls when a medication
+// doesn't list its own times.
+const firstDoseHour = 8
+
+const slotIdTime = "20060102T1504Z"
+
+// Dose is one concrete occurrence of a medication course. Doses aren't
+// stored until they're administered; their Id is derived from the course
+// and due time so the same occurrence always has the same Id.
+type Dose struct {
+	Id             string             `bson:"_id" json:"id"`
+	MedicationId   primitive.ObjectID `bson:"medicationId" json:"medicationId"`
+	AnimalId       primitive.ObjectID `bson:"animalId" json:"animalId"`
+	AnimalName     string             `bson:"animalName,omitempty" json:"animalName"`
+	Drug           string             `bson:"drug,omitempty" json:"drug"`
+	Dose           string             `bson:"dose,omitempty" json:"dose,omitempty"`
+	Due            time.Time          `bson:"due" json:"due"`
+	AdministeredAt *time.Time         `bson:"administeredAt,omitempty" json:"administeredAt,omitempty"`
+	AdministeredBy string             `bson:"administeredBy,omitempty" json:"administeredBy,omitempty"`
+	Note           string             `bson:"note,omitempty" json:"note,omitempty"`
+	Missed         bool               `bson:"-" json:"missed"`
+}
+
+func DoseId(medicationId primitive.ObjectID, due time.Time) string {
+	return slotId(medicationId, due)
+}
+
+// ParseDoseId splits a dose Id back into its medication and due time.
+func ParseDoseId(id string) (primitive.ObjectID, time.Time, error) {
+	medicationId, due, ok := parseSlotId(id)
+	if !ok {
+		return primitive.NilObjectID, time.Time{}, ErrDoseNotFound
+	}
+	return medicationId, due, nil
+}
+
+// slotId names one scheduled occurrence of something stored under id.
+func slotId(id primitive.ObjectID, due time.Time) string {
+	return id.Hex() + "-" + due.UTC().Format(slotIdTime)
+}
+
+func parseSlotId(slot string) (primitive.ObjectID, time.Time, bool) {
+	hex, at, ok := strings.Cut(slot, "-")
+	if !ok {
+		return primitive.NilObjectID, time.Time{}, false
+	}
+
+	id, err := primitive.ObjectIDFromHex(hex)
+	if err != nil {
+		return primitive.NilObjectID, time.Time{}, false
+	}
+
+	due, err := time.Parse(slotIdTime, at)
+	if err != nil {
+		return primitive.NilObjectID, time.Time{}, false
+	}
+
+	return id, due, true
+}
+
+// parseTimesOfDay turns "15:04" times into sorted minutes after midnight.
+func parseTimesOfDay(clocks []string) ([]int, error) {
+	minutes := make([]int, 0, len(clocks))
+	for _, clock := range clocks {
+		t, err := time.Parse("15:04", clock)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidTimeOfDay, clock)
+		}
+		minutes = append(minutes, t.Hour()*60+t.Minute())
+	}
+	sort.Ints(minutes)
+	return minutes, nil
+}
+
+// StartOfDay returns midnight of the day t falls on in loc.
+func StartOfDay(t time.Time, loc *time.Location) time.Time {
+	y, m, d := t.In(loc).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, loc)
+}
+
+// calendarDay returns midnight in loc of the date stored in dt. Course
+// dates are saved as UTC midnight of the day picked, whatever the farm's
+// zone.
+func calendarDay(dt primitive.DateTime, loc *time.Location) time.Time {
+	y, m, d := dt.Time().UTC().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, loc)
+}
+
+// TimesOfDay returns the minutes after midnight each daily dose is given
+// at: the medication's own Times, or TimesPerDay doses spread evenly
+// around the clock starting at 08:00.
+func (f *Frequency) TimesOfDay() ([]int, error) {
+	if len(f.Times) > 0 {
+		return parseTimesOfDay(f.Times)
+	}
+
+	if f.TimesPerDay < 1 {
+		return nil, nil
+	}
+
+	minutes := make([]int, 0, f.TimesPerDay)
+	for i := 0; i < f.TimesPerDay; i++ {
+		minutes = append(minutes, (firstDoseHour*60+i*24*60/f.TimesPerDay)%(24*60))
+	}
+	sort.Ints(minutes)
+	return minutes, nil
+}
+
+// DoseTimes expands a medication course into the due times that fall in
+// [from, to), with the course's days and times of day taken in loc.
+// As-needed courses have no schedule.
+func (m *MedicalRecord) DoseTimes(from, to time.Time, loc *time.Location) []time.Time {
+	if m.Kind != MedicalMedication || m.Frequency == nil || m.Frequency.AsNeeded {
+		return nil
+	}
+
+	minutes, err := m.Frequency.TimesOfDay()
+	if err != nil || len(minutes) == 0 {
+		return nil
+	}
+
+	everyDays := m.Frequency.EveryDays
+	if everyDays < 1 {
+		everyDays = 1
+	}
+
+	first := calendarDay(m.Date, loc)
+
+	last := StartOfDay(to, loc)
+	if m.EndDate != nil {
+		if end := calendarDay(*m.EndDate, loc); end.Before(last) {
+			last = end
+		}
+	}
+
+	var times []time.Time
+
+	for day := first; !day.After(last); day = day.AddDate(0, 0, everyDays) {
+		for _, minute := range minutes {
+			due := time.Date(day.Year(), day.Month(), day.Day(), minute/60, minute%60, 0, 0, loc)
+			if due.Before(from) || !due.Before(to) {
+				continue
+			}
+			times = append(times, due)
+		}
+	}
+
+	return times
+}
+
+// ScheduleDoses lists the doses of the given courses due in [from, to),
+// filling in the ones already administered and flagging the rest that are
+// overdue at now as missed.
+func ScheduleDoses(medications []*ActiveMedication, given []*Dose, from, to, now time.Time, loc *time.Location) []*Dose {
+	administered := make(map[string]*Dose, len(given))
+	for _, dose := range given {
+		administered[dose.Id] = dose
+	}
+
+	doses := []*Dose{}
+
+	for _, medication := range medications {
+		course := &medication.Medication
+		for _, due := range course.DoseTimes(from, to, loc) {
+			dose := &Dose{
+				Id:           DoseId(course.Id, due),
+				MedicationId: course.Id,
+				AnimalId:     medication.AnimalId,
+				AnimalName:   medication.AnimalName,
+				Drug:         course.Drug,
+				Dose:         course.Dose,
+				Due:          due,
+			}
+
+			if given, ok := administered[dose.Id]; ok {
+				dose.AdministeredAt = given.AdministeredAt
+				dose.AdministeredBy = given.AdministeredBy
+				dose.Note = given.Note
+			} else {
+				dose.Missed = now.After(due.Add(MissedDoseAfter))
+			}
+
+			doses = append(doses, dose)
+		}
+	}
+
+	sort.SliceStable(doses, func(i, j int) bool {
+		if !doses[i].Due.Equal(doses[j].Due) {
+			return doses[i].Due.Before(doses[j].Due)
+		}
+		return doses[i].AnimalName < doses[j].AnimalName
+	})
+
+	return doses
+}
+
+type DoseRepository interface {
+	Between(ctx context.Context, from, to time.Time) ([]*Dose, error)
+	Administer(ctx context.Context, dose Dose) error
+	DeleteForMedication(ctx context.Context, medicationId primitive.ObjectID) error
+	DeleteForAnimal(ctx context.Context, animalId primitive.ObjectID) error
+}
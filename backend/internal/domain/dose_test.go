@@ -0,0 +1,190 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func courseDate(y int, m time.Month, d int) primitive.DateTime {
+	return primitive.NewDateTimeFromTime(time.Date(y, m, d, 0, 0, 0, 0, time.UTC))
+}
+
+func course(start primitive.DateTime, end *primitive.DateTime, frequency Frequency) *MedicalRecord {
+	return &MedicalRecord{
+		Id:        primitive.NewObjectID(),
+		Kind:      MedicalMedication,
+		Date:      start,
+		EndDate:   end,
+		Drug:      "Meloxicam",
+		Frequency: &frequency,
+	}
+}
+
+func TestDoseTimes(t *testing.T) {
+	chicago, err := time.LoadLocation("America/Chicago")
+	if err != nil {
+		t.Skip("no tz data:", err)
+	}
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Skip("no tz data:", err)
+	}
+
+	end := courseDate(2024, time.March, 7)
+
+	tests := []struct {
+		name   string
+		course *MedicalRecord
+		loc    *time.Location
+		from   time.Time
+		to     time.Time
+		want   []string
+	}{
+		{
+			name:   "west of utc starts on its own day",
+			course: course(courseDate(2024, time.March, 5), &end, Frequency{TimesPerDay: 2, EveryDays: 1}),
+			loc:    chicago,
+			from:   time.Date(2024, time.March, 4, 0, 0, 0, 0, chicago),
+			to:     time.Date(2024, time.March, 9, 0, 0, 0, 0, chicago),
+			want: []string{
+				"2024-03-05 08:00", "2024-03-05 20:00",
+				"2024-03-06 08:00", "2024-03-06 20:00",
+				"2024-03-07 08:00", "2024-03-07 20:00",
+			},
+		},
+		{
+			name:   "east of utc keeps the first morning",
+			course: course(courseDate(2024, time.March, 5), &end, Frequency{Times: []string{"07:30"}, EveryDays: 1}),
+			loc:    tokyo,
+			from:   time.Date(2024, time.March, 5, 0, 0, 0, 0, tokyo),
+			to:     time.Date(2024, time.March, 8, 0, 0, 0, 0, tokyo),
+			want:   []string{"2024-03-05 07:30", "2024-03-06 07:30", "2024-03-07 07:30"},
+		},
+		{
+			name:   "every other day counts from the start",
+			course: course(courseDate(2024, time.March, 1), nil, Frequency{Times: []string{"09:00"}, EveryDays: 2}),
+			loc:    chicago,
+			from:   time.Date(2024, time.March, 4, 0, 0, 0, 0, chicago),
+			to:     time.Date(2024, time.March, 8, 0, 0, 0, 0, chicago),
+			want:   []string{"2024-03-05 09:00", "2024-03-07 09:00"},
+		},
+		{
+			name:   "across the spring change",
+			course: course(courseDate(2024, time.March, 9), nil, Frequency{Times: []string{"08:00"}, EveryDays: 1}),
+			loc:    chicago,
+			from:   time.Date(2024, time.March, 9, 0, 0, 0, 0, chicago),
+			to:     time.Date(2024, time.March, 11, 0, 0, 0, 0, chicago),
+			want:   []string{"2024-03-09 08:00", "2024-03-10 08:00"},
+		},
+		{
+			name:   "window cuts the day",
+			course: course(courseDate(2024, time.March, 5), nil, Frequency{TimesPerDay: 3, EveryDays: 1}),
+			loc:    chicago,
+			from:   time.Date(2024, time.March, 5, 12, 0, 0, 0, chicago),
+			to:     time.Date(2024, time.March, 6, 8, 0, 0, 0, chicago),
+			want:   []string{"2024-03-05 16:00", "2024-03-06 00:00"},
+		},
+		{
+			name:   "as needed",
+			course: course(courseDate(2024, time.March, 5), nil, Frequency{AsNeeded: true}),
+			loc:    chicago,
+			from:   time.Date(2024, time.March, 5, 0, 0, 0, 0, chicago),
+			to:     time.Date(2024, time.March, 6, 0, 0, 0, 0, chicago),
+		},
+		{
+			name:   "ended before the window",
+			course: course(courseDate(2024, time.March, 1), &end, Frequency{TimesPerDay: 1, EveryDays: 1}),
+			loc:    chicago,
+			from:   time.Date(2024, time.March, 8, 0, 0, 0, 0, chicago),
+			to:     time.Date(2024, time.March, 9, 0, 0, 0, 0, chicago),
+		},
+	}
+
+	for _, tt := range tests {
+		var got []string
+		for _, due := range tt.course.DoseTimes(tt.from, tt.to, tt.loc) {
+			if due.Location() != tt.loc {
+				t.Errorf("%s: due %v not in %v", tt.name, due, tt.loc)
+			}
+			got = append(got, due.Format("2006-01-02 15:04"))
+		}
+
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: DoseTimes = %v, want %v", tt.name, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("%s: DoseTimes = %v, want %v", tt.name, got, tt.want)
+				break
+			}
+		}
+	}
+}
+
+func TestScheduleDoses(t *testing.T) {
+	chicago, err := time.LoadLocation("America/Chicago")
+	if err != nil {
+		t.Skip("no tz data:", err)
+	}
+
+	from := time.Date(2024, time.March, 5, 0, 0, 0, 0, chicago)
+	to := from.AddDate(0, 0, 1)
+	now := time.Date(2024, time.March, 5, 12, 0, 0, 0, chicago)
+
+	tom := &ActiveMedication{
+		AnimalId:   primitive.NewObjectID(),
+		AnimalName: "Tom",
+		Medication: *course(courseDate(2024, time.March, 5), nil, Frequency{Times: []string{"08:00", "20:00"}, EveryDays: 1}),
+	}
+	bella := &ActiveMedication{
+		AnimalId:   primitive.NewObjectID(),
+		AnimalName: "Bella",
+		Medication: *course(courseDate(2024, time.March, 4), nil, Frequency{Times: []string{"08:00"}, EveryDays: 1}),
+	}
+
+	morning := time.Date(2024, time.March, 5, 8, 0, 0, 0, chicago)
+	givenAt := morning.Add(10 * time.Minute)
+	given := []*Dose{{
+		Id:             DoseId(tom.Medication.Id, morning),
+		AdministeredAt: &givenAt,
+		AdministeredBy: "sam",
+	}}
+
+	doses := ScheduleDoses([]*ActiveMedication{tom, bella}, given, from, to, now, chicago)
+
+	want := []struct {
+		animal string
+		due    string
+		given  bool
+		missed bool
+	}{
+		{"Bella", "08:00", false, true},
+		{"Tom", "08:00", true, false},
+		{"Tom", "20:00", false, false},
+	}
+
+	if len(doses) != len(want) {
+		t.Fatalf("ScheduleDoses returned %d doses, want %d", len(doses), len(want))
+	}
+
+	for i, w := range want {
+		dose := doses[i]
+		if dose.AnimalName != w.animal || dose.Due.Format("15:04") != w.due {
+			t.Errorf("dose %d = %s at %s, want %s at %s", i, dose.AnimalName, dose.Due.Format("15:04"), w.animal, w.due)
+		}
+		if (dose.AdministeredAt != nil) != w.given {
+			t.Errorf("dose %d administered = %v, want %v", i, dose.AdministeredAt != nil, w.given)
+		}
+		if dose.Missed != w.missed {
+			t.Errorf("dose %d missed = %v, want %v", i, dose.Missed, w.missed)
+		}
+
+		medicationId, due, err := ParseDoseId(dose.Id)
+		if err != nil || medicationId != dose.MedicationId || !due.Equal(dose.Due) {
+			t.Errorf("dose %d id %q doesn't round trip", i, dose.Id)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	ErrEggLogNotFound   = errors.New("egg log not found")
+	ErrEggLogTarget     = errors.New("egg log needs exactly one of animalId or coop")
+	ErrInvalidEggCount  = errors.New("egg count must not be negative")
+	ErrNotALayer        = errors.New("eggs can only be logged for animal types that lay eggs")
+	ErrInvalidEggGroup  = errors.New("groupBy must be breed, hen or coop")
+	ErrInvalidEggWindow = errors.New("window must be between 1 and 366")
+)
+
+const DefaultEggWindow = 7
+
+// EggLog is the number of eggs collected on one day, either from a single
+// hen or from a whole coop when the birds aren't told apart. Date is always
+// midnight UTC of the calendar day, see EggDay.
+type EggLog struct {
+	Id         primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	Date       primitive.DateTime  `bson:"date" json:"date"`
+	AnimalId   *primitive.ObjectID `bson:"animalId,omitempty" json:"animalId,omitempty"`
+	Coop       string              `bson:"coop,omitempty" json:"coop,omitempty"`
+	Count      int                 `bson:"count" json:"count"`
+	Note       string              `bson:"note,omitempty" json:"note,omitempty"`
+	RecordedBy string              `bson:"recordedBy,omitempty" json:"recordedBy,omitempty"`
+}
+
+func (e *EggLog) Validate() error {
+	if (e.AnimalId == nil) == (e.Coop == "") {
+		return ErrEggLogTarget
+	}
+
+	if e.Count < 0 {
+		return ErrInvalidEggCount
+	}
+
+	return nil
+}
+
+// EggDay returns the calendar day t falls on in loc, as midnight UTC, so
+// logs from the same day always compare equal.
+func EggDay(t time.Time, loc *time.Location) time.Time {
+	y, m, d := t.In(loc).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+type EggLogQuery struct {
+	From     time.Time
+	To       time.Time
+	AnimalId *primitive.ObjectID
+	Coop     string
+}
+
+type EggInterval string
+
+const (
+	EggDaily   EggInterval = "day"
+	EggWeekly  EggInterval = "week"
+	EggMonthly EggInterval = "month"
+)
+
+func (i EggInterval) Valid() bool {
+	return i == EggDaily || i == EggWeekly || i == EggMonthly
+}
+
+type EggGroup string
+
+const (
+	EggTotal  EggGroup = ""
+	EggByBird EggGroup = "hen"
+	EggBreed  EggGroup = "breed"
+	EggCoop   EggGroup = "coop"
+)
+
+func (g EggGroup) Valid() bool {
+	return g == EggTotal || g == EggByBird || g == EggBreed || g == EggCoop
+}
+
+// EggStatsQuery selects the logs in [From, To) and totals them per Interval
+// and Group. RollingAverage covers the last Window periods of the group
+// that have logs.
+type EggStatsQuery struct {
+	From     time.Time
+	To       time.Time
+	Interval EggInterval
+	Group    EggGroup
+	Window   int
+}
+
+type EggStat struct {
+	Period         time.Time `bson:"period" json:"period"`
+	Group          string    `bson:"group" json:"group,omitempty"`
+	Label          string    `bson:"label" json:"label,omitempty"`
+	Eggs           int       `bson:"eggs" json:"eggs"`
+	Logs           int       `bson:"logs" json:"logs"`
+	RollingAverage float64   `bson:"rollingAverage" json:"rollingAverage"`
+}
+
+// EggStreak is a hen's run of consecutive days with at least one egg.
+// The current streak is still alive when its last day is today or
+// yesterday, since today's eggs may not be collected yet.
+type EggStreak struct {
+	AnimalId   primitive.ObjectID `bson:"animalId" json:"animalId"`
+	Name       string             `bson:"name" json:"name"`
+	Breed      AnimalBreed        `bson:"breed" json:"breed,omitempty"`
+	Longest    int                `bson:"longest" json:"longest"`
+	Current    int                `bson:"-" json:"current"`
+	LastLaid   time.Time          `bson:"lastLaid" json:"lastLaid"`
+	LastLength int                `bson:"lastLength" json:"-"`
+}
+
+func (s *EggStreak) SetCurrent(today time.Time) {
+	s.Current = 0
+	if !s.LastLaid.Before(today.AddDate(0, 0, -1)) {
+		s.Current = s.LastLength
+	}
+}
+
+type EggRepository interface {
+	Find(ctx context.Context, query EggLogQuery) ([]*EggLog, error)
+	Record(ctx context.Context, log EggLog) (*EggLog, error)
+	Delete(ctx context.Context, id string) error
+	DeleteForAnimal(ctx context.Context, animalId primitive.ObjectID) error
+	Stats(ctx context.Context, query EggStatsQuery) ([]*EggStat, error)
+	Streaks(ctx context.Context, since time.Time) ([]*EggStreak, error)
+}
@@ -0,0 +1,172 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	ErrFeedingPlanNotFound = errors.New("feeding plan not found")
+	ErrFeedingNotFound     = errors.New("feeding not found")
+	ErrAlreadyFed          = errors.New("this feeding has already been given")
+	ErrFeedingTarget       = errors.New("feeding plan needs animalIds or a type, not both")
+	ErrMissingFood         = errors.New("feeding plan needs a food")
+	ErrInvalidAmount       = errors.New("amount must be a positive number")
+	ErrMissingFeedingTimes = errors.New("feeding plan needs at least one time of day")
+	ErrPlanPaused          = errors.New("feeding plan is paused")
+)
+
+// FeedingPlan is what a set of animals gets fed and when. It covers either
+// the listed animals or every animal of Type. Paused plans are kept but
+// left off the daily schedule.
+type FeedingPlan struct {
+	Id        primitive.ObjectID   `bson:"_id,omitempty" json:"id"`
+	Name      string               `bson:"name,omitempty" json:"name,omitempty"`
+	AnimalIds []primitive.ObjectID `bson:"animalIds,omitempty" json:"animalIds,omitempty"`
+	Type      AnimalType           `bson:"type,omitempty" json:"type,omitempty"`
+	Food      string               `bson:"food" json:"food"`
+	Amount    float64              `bson:"amount" json:"amount"`
+	Unit      string               `bson:"unit,omitempty" json:"unit,omitempty"`
+	Times     []string             `bson:"times" json:"times"`
+	Notes     string               `bson:"notes,omitempty" json:"notes,omitempty"`
+	Paused    bool                 `bson:"paused,omitempty" json:"paused,omitempty"`
+}
+
+func (p *FeedingPlan) Validate() error {
+	if (len(p.AnimalIds) == 0) == (p.Type == 0) {
+		return ErrFeedingTarget
+	}
+
+	if p.Food == "" {
+		return ErrMissingFood
+	}
+
+	if p.Amount <= 0 || math.IsNaN(p.Amount) || math.IsInf(p.Amount, 0) {
+		return ErrInvalidAmount
+	}
+
+	if len(p.Times) == 0 {
+		return ErrMissingFeedingTimes
+	}
+
+	if _, err := parseTimesOfDay(p.Times); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// FeedingTimes returns the plan's feeding times that fall in [from, to),
+// with times of day taken in loc.
+func (p *FeedingPlan) FeedingTimes(from, to time.Time, loc *time.Location) []time.Time {
+	minutes, err := parseTimesOfDay(p.Times)
+	if err != nil {
+		return nil
+	}
+
+	var times []time.Time
+
+	for day := StartOfDay(from, loc); day.Before(to); day = day.AddDate(0, 0, 1) {
+		for _, minute := range minutes {
+			due := time.Date(day.Year(), day.Month(), day.Day(), minute/60, minute%60, 0, 0, loc)
+			if due.Before(from) || !due.Before(to) {
+				continue
+			}
+			times = append(times, due)
+		}
+	}
+
+	return times
+}
+
+// Feeding is one scheduled feeding of a plan. Like doses, feedings are only
+// stored once given, under an Id made from the plan and due time, so the
+// same feeding can't be recorded twice.
+type Feeding struct {
+	Id        string               `bson:"_id" json:"id"`
+	PlanId    primitive.ObjectID   `bson:"planId" json:"planId"`
+	Plan      string               `bson:"plan,omitempty" json:"plan,omitempty"`
+	AnimalIds []primitive.ObjectID `bson:"animalIds,omitempty" json:"animalIds,omitempty"`
+	Type      AnimalType           `bson:"type,omitempty" json:"type,omitempty"`
+	Food      string               `bson:"food" json:"food"`
+	Amount    float64              `bson:"amount" json:"amount"`
+	Unit      string               `bson:"unit,omitempty" json:"unit,omitempty"`
+	Due       time.Time            `bson:"due" json:"due"`
+	FedAt     *time.Time           `bson:"fedAt,omitempty" json:"fedAt,omitempty"`
+	FedBy     string               `bson:"fedBy,omitempty" json:"fedBy,omitempty"`
+	Note      string               `bson:"note,omitempty" json:"note,omitempty"`
+}
+
+func FeedingId(planId primitive.ObjectID, due time.Time) string {
+	return slotId(planId, due)
+}
+
+// ParseFeedingId splits a feeding Id back into its plan and due time.
+func ParseFeedingId(id string) (primitive.ObjectID, time.Time, error) {
+	planId, due, ok := parseSlotId(id)
+	if !ok {
+		return primitive.NilObjectID, time.Time{}, ErrFeedingNotFound
+	}
+	return planId, due, nil
+}
+
+// NewFeeding is the not yet given feeding of plan at due.
+func NewFeeding(plan *FeedingPlan, due time.Time) *Feeding {
+	return &Feeding{
+		Id:        FeedingId(plan.Id, due),
+		PlanId:    plan.Id,
+		Plan:      plan.Name,
+		AnimalIds: plan.AnimalIds,
+		Type:      plan.Type,
+		Food:      plan.Food,
+		Amount:    plan.Amount,
+		Unit:      plan.Unit,
+		Due:       due,
+	}
+}
+
+// ScheduleFeedings lists the feedings of plans due in [from, to), filling in
+// the ones already given.
+func ScheduleFeedings(plans []*FeedingPlan, given []*Feeding, from, to time.Time, loc *time.Location) []*Feeding {
+	fed := make(map[string]*Feeding, len(given))
+	for _, feeding := range given {
+		fed[feeding.Id] = feeding
+	}
+
+	feedings := []*Feeding{}
+
+	for _, plan := range plans {
+		for _, due := range plan.FeedingTimes(from, to, loc) {
+			feeding := NewFeeding(plan, due)
+			if given, ok := fed[feeding.Id]; ok {
+				feeding.FedAt = given.FedAt
+				feeding.FedBy = given.FedBy
+				feeding.Note = given.Note
+			}
+			feedings = append(feedings, feeding)
+		}
+	}
+
+	sort.SliceStable(feedings, func(i, j int) bool {
+		return feedings[i].Due.Before(feedings[j].Due)
+	})
+
+	return feedings
+}
+
+type FeedingRepository interface {
+	Plans(ctx context.Context, activeOnly bool) ([]*FeedingPlan, error)
+	PlansFor(ctx context.Context, animal *Animal) ([]*FeedingPlan, error)
+	GetPlan(ctx context.Context, id string) (*FeedingPlan, error)
+	InsertPlan(ctx context.Context, plan FeedingPlan) (*FeedingPlan, error)
+	UpdatePlan(ctx context.Context, id string, plan FeedingPlan) error
+	DeletePlan(ctx context.Context, id string) error
+	RemoveAnimal(ctx context.Context, animalId primitive.ObjectID) error
+	Feedings(ctx context.Context, from, to time.Time) ([]*Feeding, error)
+	Feed(ctx context.Context, feeding Feeding) error
+}
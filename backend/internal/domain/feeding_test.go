@@ -0,0 +1,164 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestFeedingPlanValidate(t *testing.T) {
+	valid := func() FeedingPlan {
+		return FeedingPlan{Type: ChickenType, Food: "Layer pellets", Amount: 2, Unit: "cups", Times: []string{"07:00", "17:00"}}
+	}
+
+	tests := []struct {
+		name   string
+		change func(*FeedingPlan)
+		want   error
+	}{
+		{"valid", func(p *FeedingPlan) {}, nil},
+		{"listed animals", func(p *FeedingPlan) { p.Type = 0; p.AnimalIds = []primitive.ObjectID{primitive.NewObjectID()} }, nil},
+		{"no target", func(p *FeedingPlan) { p.Type = 0 }, ErrFeedingTarget},
+		{"both targets", func(p *FeedingPlan) { p.AnimalIds = []primitive.ObjectID{primitive.NewObjectID()} }, ErrFeedingTarget},
+		{"no food", func(p *FeedingPlan) { p.Food = "" }, ErrMissingFood},
+		{"no amount", func(p *FeedingPlan) { p.Amount = 0 }, ErrInvalidAmount},
+		{"no times", func(p *FeedingPlan) { p.Times = nil }, ErrMissingFeedingTimes},
+	}
+
+	for _, tt := range tests {
+		plan := valid()
+		tt.change(&plan)
+		if err := plan.Validate(); err != tt.want {
+			t.Errorf("%s: Validate = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+
+	plan := valid()
+	plan.Times = []string{"7am"}
+	if err := plan.Validate(); err == nil {
+		t.Error("bad time of day: Validate = nil, want an error")
+	}
+}
+
+func TestFeedingTimes(t *testing.T) {
+	chicago, err := time.LoadLocation("America/Chicago")
+	if err != nil {
+		t.Skip("no tz data:", err)
+	}
+
+	tests := []struct {
+		name  string
+		times []string
+		from  time.Time
+		to    time.Time
+		want  []string
+	}{
+		{
+			name:  "one day sorted",
+			times: []string{"17:00", "07:00"},
+			from:  time.Date(2024, time.March, 5, 0, 0, 0, 0, chicago),
+			to:    time.Date(2024, time.March, 6, 0, 0, 0, 0, chicago),
+			want:  []string{"2024-03-05 07:00", "2024-03-05 17:00"},
+		},
+		{
+			name:  "window starts mid day",
+			times: []string{"07:00", "17:00"},
+			from:  time.Date(2024, time.March, 5, 12, 0, 0, 0, chicago),
+			to:    time.Date(2024, time.March, 6, 12, 0, 0, 0, chicago),
+			want:  []string{"2024-03-05 17:00", "2024-03-06 07:00"},
+		},
+		{
+			name:  "end is exclusive",
+			times: []string{"07:00"},
+			from:  time.Date(2024, time.March, 5, 0, 0, 0, 0, chicago),
+			to:    time.Date(2024, time.March, 5, 7, 0, 0, 0, chicago),
+		},
+		{
+			name:  "across the spring change",
+			times: []string{"07:00"},
+			from:  time.Date(2024, time.March, 9, 0, 0, 0, 0, chicago),
+			to:    time.Date(2024, time.March, 11, 0, 0, 0, 0, chicago),
+			want:  []string{"2024-03-09 07:00", "2024-03-10 07:00"},
+		},
+		{
+			name:  "from given in utc",
+			times: []string{"20:00"},
+			from:  time.Date(2024, time.March, 6, 0, 0, 0, 0, time.UTC),
+			to:    time.Date(2024, time.March, 6, 6, 0, 0, 0, time.UTC),
+			want:  []string{"2024-03-05 20:00"},
+		},
+		{
+			name:  "bad times",
+			times: []string{"later"},
+			from:  time.Date(2024, time.March, 5, 0, 0, 0, 0, chicago),
+			to:    time.Date(2024, time.March, 6, 0, 0, 0, 0, chicago),
+		},
+	}
+
+	for _, tt := range tests {
+		plan := &FeedingPlan{Times: tt.times}
+
+		var got []string
+		for _, due := range plan.FeedingTimes(tt.from, tt.to, chicago) {
+			got = append(got, due.In(chicago).Format("2006-01-02 15:04"))
+		}
+
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: FeedingTimes = %v, want %v", tt.name, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("%s: FeedingTimes = %v, want %v", tt.name, got, tt.want)
+				break
+			}
+		}
+	}
+}
+
+func TestScheduleFeedings(t *testing.T) {
+	from := time.Date(2024, time.March, 5, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 1)
+
+	hens := &FeedingPlan{Id: primitive.NewObjectID(), Name: "Hens", Type: ChickenType, Food: "Layer pellets", Amount: 2, Times: []string{"07:00", "17:00"}}
+	dogs := &FeedingPlan{Id: primitive.NewObjectID(), Name: "Dogs", Type: DogType, Food: "Kibble", Amount: 1, Times: []string{"12:00"}}
+
+	fedAt := time.Date(2024, time.March, 5, 7, 5, 0, 0, time.UTC)
+	given := []*Feeding{{Id: FeedingId(hens.Id, time.Date(2024, time.March, 5, 7, 0, 0, 0, time.UTC)), FedAt: &fedAt, FedBy: "sam"}}
+
+	feedings := ScheduleFeedings([]*FeedingPlan{hens, dogs}, given, from, to, time.UTC)
+
+	want := []struct {
+		plan string
+		due  string
+		fed  bool
+	}{
+		{"Hens", "07:00", true},
+		{"Dogs", "12:00", false},
+		{"Hens", "17:00", false},
+	}
+
+	if len(feedings) != len(want) {
+		t.Fatalf("ScheduleFeedings returned %d feedings, want %d", len(feedings), len(want))
+	}
+
+	for i, w := range want {
+		feeding := feedings[i]
+		if feeding.Plan != w.plan || feeding.Due.Format("15:04") != w.due {
+			t.Errorf("feeding %d = %s at %s, want %s at %s", i, feeding.Plan, feeding.Due.Format("15:04"), w.plan, w.due)
+		}
+		if (feeding.FedAt != nil) != w.fed {
+			t.Errorf("feeding %d fed = %v, want %v", i, feeding.FedAt != nil, w.fed)
+		}
+
+		planId, due, err := ParseFeedingId(feeding.Id)
+		if err != nil || planId != feeding.PlanId || !due.Equal(feeding.Due) {
+			t.Errorf("feeding %d id %q doesn't round trip", i, feeding.Id)
+		}
+	}
+
+	if feedings[0].FedBy != "sam" {
+		t.Errorf("FedBy = %q, want sam", feedings[0].FedBy)
+	}
+}
@@ -0,0 +1,230 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	ErrFieldNotFound    = errors.New("field not found")
+	ErrFieldExists      = errors.New("this type already has a field with that key")
+	ErrInvalidFieldKey  = errors.New("field key must start with a letter and hold only letters, digits and underscores")
+	ErrInvalidFieldKind = errors.New("field kind must be string, number, date, enum or boolean")
+	ErrMissingFieldType = errors.New("field needs a type")
+	ErrMissingOptions   = errors.New("enum fields need options")
+	ErrUnknownAttribute = errors.New("unknown attribute")
+	ErrMissingAttribute = errors.New("missing required attribute")
+	ErrInvalidAttribute = errors.New("invalid attribute value")
+	ErrAttributeRange   = errors.New("only number and date attributes take min and max")
+	ErrFieldKindChange  = errors.New("a field's kind can't be changed")
+	ErrFieldUnfilled    = errors.New("animals of this type have no value for the field yet")
+)
+
+var fieldKeyPattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*$`)
+
+type FieldKind string
+
+const (
+	FieldString  FieldKind = "string"
+	FieldNumber  FieldKind = "number"
+	FieldDate    FieldKind = "date"
+	FieldEnum    FieldKind = "enum"
+	FieldBoolean FieldKind = "boolean"
+)
+
+func (k FieldKind) Valid() bool {
+	switch k {
+	case FieldString, FieldNumber, FieldDate, FieldEnum, FieldBoolean:
+		return true
+	}
+	return false
+}
+
+// FieldDefinition is a custom attribute animals of one type can carry,
+// such as comb type for chickens. Values live in Animal.Attributes under
+// Key.
+type FieldDefinition struct {
+	Id       primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Type     AnimalType         `bson:"type" json:"type"`
+	Key      string             `bson:"key" json:"key"`
+	Label    string             `bson:"label,omitempty" json:"label,omitempty"`
+	Kind     FieldKind          `bson:"kind" json:"kind"`
+	Options  []string           `bson:"options,omitempty" json:"options,omitempty"`
+	Required bool               `bson:"required,omitempty" json:"required,omitempty"`
+}
+
+func (f *FieldDefinition) Validate() error {
+	if f.Type == 0 {
+		return ErrMissingFieldType
+	}
+
+	if !fieldKeyPattern.MatchString(f.Key) {
+		return ErrInvalidFieldKey
+	}
+
+	if !f.Kind.Valid() {
+		return ErrInvalidFieldKind
+	}
+
+	if f.Kind == FieldEnum && len(f.Options) == 0 {
+		return ErrMissingOptions
+	}
+
+	return nil
+}
+
+// parse converts a value written as a string, from a query string or a
+// date in JSON, to what is stored for the field.
+func (f *FieldDefinition) parse(v string) (interface{}, bool) {
+	switch f.Kind {
+	case FieldString:
+		return v, true
+	case FieldEnum:
+		for _, option := range f.Options {
+			if option == v {
+				return v, true
+			}
+		}
+	case FieldNumber:
+		if n, err := strconv.ParseFloat(v, 64); err == nil {
+			return n, true
+		}
+	case FieldBoolean:
+		if b, err := strconv.ParseBool(v); err == nil {
+			return b, true
+		}
+	case FieldDate:
+		if t, err := time.Parse(time.RFC3339, v); err == nil {
+			return t.UTC(), true
+		}
+		if t, err := time.Parse("2006-01-02", v); err == nil {
+			return t, true
+		}
+	}
+	return nil, false
+}
+
+// value checks a decoded JSON value against the field.
+func (f *FieldDefinition) value(v interface{}) (interface{}, bool) {
+	switch v := v.(type) {
+	case string:
+		if f.Kind == FieldNumber || f.Kind == FieldBoolean {
+			return nil, false
+		}
+		return f.parse(v)
+	case float64:
+		return v, f.Kind == FieldNumber
+	case bool:
+		return v, f.Kind == FieldBoolean
+	}
+	return nil, false
+}
+
+// ValidateAttributes checks attributes against the fields defined for an
+// animal's type and returns them as they should be stored: dates become
+// times and null values are dropped.
+func ValidateAttributes(fields []*FieldDefinition, attributes map[string]interface{}) (map[string]interface{}, error) {
+	byKey := make(map[string]*FieldDefinition, len(fields))
+	for _, field := range fields {
+		byKey[field.Key] = field
+	}
+
+	result := make(map[string]interface{}, len(attributes))
+
+	for key, v := range attributes {
+		field, ok := byKey[key]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownAttribute, key)
+		}
+
+		if v == nil {
+			continue
+		}
+
+		value, ok := field.value(v)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidAttribute, key)
+		}
+		result[key] = value
+	}
+
+	for _, field := range fields {
+		if _, ok := result[field.Key]; field.Required && !ok {
+			return nil, fmt.Errorf("%w: %s", ErrMissingAttribute, field.Key)
+		}
+	}
+
+	if len(result) == 0 {
+		return nil, nil
+	}
+
+	return result, nil
+}
+
+type AttributeOp string
+
+const (
+	AttributeIn  AttributeOp = "in"
+	AttributeMin AttributeOp = "min"
+	AttributeMax AttributeOp = "max"
+)
+
+// AttributeFilter narrows an animal listing on a custom attribute:
+// ?attr.combType=pea,rose matches either value, ?attr.weight.min=2 and
+// ?attr.weight.max=4 bound numbers and dates. Raw holds the values as
+// written; ResolveAttributeFilters turns them into Values.
+type AttributeFilter struct {
+	Key    string
+	Op     AttributeOp
+	Raw    []string
+	Values []interface{}
+}
+
+// ResolveAttributeFilters converts each filter's values to its field's
+// kind. A key may be defined for several types; the first definition wins.
+func ResolveAttributeFilters(fields []*FieldDefinition, filters []AttributeFilter) error {
+	byKey := make(map[string]*FieldDefinition, len(fields))
+	for _, field := range fields {
+		if _, ok := byKey[field.Key]; !ok {
+			byKey[field.Key] = field
+		}
+	}
+
+	for i := range filters {
+		filter := &filters[i]
+
+		field, ok := byKey[filter.Key]
+		if !ok {
+			return fmt.Errorf("%w: %s", ErrUnknownAttribute, filter.Key)
+		}
+
+		if filter.Op != AttributeIn && field.Kind != FieldNumber && field.Kind != FieldDate {
+			return fmt.Errorf("%w: %s", ErrAttributeRange, filter.Key)
+		}
+
+		filter.Values = nil
+		for _, raw := range filter.Raw {
+			value, ok := field.parse(raw)
+			if !ok {
+				return fmt.Errorf("%w: %s", ErrInvalidAttribute, filter.Key)
+			}
+			filter.Values = append(filter.Values, value)
+		}
+	}
+
+	return nil
+}
+
+type FieldRepository interface {
+	List(ctx context.Context, animalType AnimalType) ([]*FieldDefinition, error)
+	GetById(ctx context.Context, id string) (*FieldDefinition, error)
+	Insert(ctx context.Context, field FieldDefinition) (*FieldDefinition, error)
+	Update(ctx context.Context, id string, field FieldDefinition) error
+	Delete(ctx context.Context, id string) error
+}
@@ -0,0 +1,155 @@
+package domain
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func chickenFields() []*FieldDefinition {
+	return []*FieldDefinition{
+		{Type: ChickenType, Key: "combType", Kind: FieldEnum, Options: []string{"single", "pea", "rose"}},
+		{Type: ChickenType, Key: "weight", Kind: FieldNumber},
+		{Type: ChickenType, Key: "hatched", Kind: FieldDate},
+		{Type: ChickenType, Key: "broody", Kind: FieldBoolean},
+		{Type: ChickenType, Key: "coop", Kind: FieldString, Required: true},
+	}
+}
+
+func TestFieldDefinitionValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		field FieldDefinition
+		want  error
+	}{
+		{"valid", FieldDefinition{Type: ChickenType, Key: "combType", Kind: FieldEnum, Options: []string{"pea"}}, nil},
+		{"no type", FieldDefinition{Key: "combType", Kind: FieldString}, ErrMissingFieldType},
+		{"bad key", FieldDefinition{Type: ChickenType, Key: "comb-type", Kind: FieldString}, ErrInvalidFieldKey},
+		{"key starts with a digit", FieldDefinition{Type: ChickenType, Key: "2nd", Kind: FieldString}, ErrInvalidFieldKey},
+		{"bad kind", FieldDefinition{Type: ChickenType, Key: "comb", Kind: "list"}, ErrInvalidFieldKind},
+		{"enum without options", FieldDefinition{Type: ChickenType, Key: "comb", Kind: FieldEnum}, ErrMissingOptions},
+	}
+
+	for _, tt := range tests {
+		if err := tt.field.Validate(); err != tt.want {
+			t.Errorf("%s: Validate = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestValidateAttributes(t *testing.T) {
+	tests := []struct {
+		name       string
+		attributes map[string]interface{}
+		want       map[string]interface{}
+		wantErr    error
+	}{
+		{
+			name:       "every kind",
+			attributes: map[string]interface{}{"combType": "pea", "weight": 2.5, "hatched": "2023-04-01", "broody": true, "coop": "north"},
+			want: map[string]interface{}{
+				"combType": "pea",
+				"weight":   2.5,
+				"hatched":  time.Date(2023, time.April, 1, 0, 0, 0, 0, time.UTC),
+				"broody":   true,
+				"coop":     "north",
+			},
+		},
+		{
+			name:       "timestamp dates are stored in utc",
+			attributes: map[string]interface{}{"hatched": "2023-04-01T08:00:00-05:00", "coop": "north"},
+			want:       map[string]interface{}{"hatched": time.Date(2023, time.April, 1, 13, 0, 0, 0, time.UTC), "coop": "north"},
+		},
+		{
+			name:       "nulls are dropped",
+			attributes: map[string]interface{}{"weight": nil, "coop": "north"},
+			want:       map[string]interface{}{"coop": "north"},
+		},
+		{
+			name:       "unknown key",
+			attributes: map[string]interface{}{"wattles": "red", "coop": "north"},
+			wantErr:    ErrUnknownAttribute,
+		},
+		{
+			name:       "option not listed",
+			attributes: map[string]interface{}{"combType": "walnut", "coop": "north"},
+			wantErr:    ErrInvalidAttribute,
+		},
+		{
+			name:       "number as a string",
+			attributes: map[string]interface{}{"weight": "2.5", "coop": "north"},
+			wantErr:    ErrInvalidAttribute,
+		},
+		{
+			name:       "boolean as a string",
+			attributes: map[string]interface{}{"broody": "true", "coop": "north"},
+			wantErr:    ErrInvalidAttribute,
+		},
+		{
+			name:       "string as a number",
+			attributes: map[string]interface{}{"coop": 3.0},
+			wantErr:    ErrInvalidAttribute,
+		},
+		{
+			name:       "bad date",
+			attributes: map[string]interface{}{"hatched": "April 1st", "coop": "north"},
+			wantErr:    ErrInvalidAttribute,
+		},
+		{
+			name:       "required missing",
+			attributes: map[string]interface{}{"weight": 2.5},
+			wantErr:    ErrMissingAttribute,
+		},
+		{
+			name:       "required null",
+			attributes: map[string]interface{}{"coop": nil},
+			wantErr:    ErrMissingAttribute,
+		},
+	}
+
+	for _, tt := range tests {
+		got, err := ValidateAttributes(chickenFields(), tt.attributes)
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("%s: err = %v, want %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if err == nil && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: ValidateAttributes = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	got, err := ValidateAttributes(nil, map[string]interface{}{})
+	if err != nil || got != nil {
+		t.Errorf("no fields: ValidateAttributes = %v, %v, want nil, nil", got, err)
+	}
+}
+
+func TestResolveAttributeFilters(t *testing.T) {
+	tests := []struct {
+		name    string
+		filter  AttributeFilter
+		want    []interface{}
+		wantErr error
+	}{
+		{"enum values", AttributeFilter{Key: "combType", Op: AttributeIn, Raw: []string{"pea", "rose"}}, []interface{}{"pea", "rose"}, nil},
+		{"number bound", AttributeFilter{Key: "weight", Op: AttributeMin, Raw: []string{"2"}}, []interface{}{2.0}, nil},
+		{"date bound", AttributeFilter{Key: "hatched", Op: AttributeMax, Raw: []string{"2023-04-01"}}, []interface{}{time.Date(2023, time.April, 1, 0, 0, 0, 0, time.UTC)}, nil},
+		{"boolean", AttributeFilter{Key: "broody", Op: AttributeIn, Raw: []string{"true"}}, []interface{}{true}, nil},
+		{"unknown key", AttributeFilter{Key: "wattles", Op: AttributeIn, Raw: []string{"red"}}, nil, ErrUnknownAttribute},
+		{"range on a string", AttributeFilter{Key: "coop", Op: AttributeMin, Raw: []string{"a"}}, nil, ErrAttributeRange},
+		{"bad number", AttributeFilter{Key: "weight", Op: AttributeMax, Raw: []string{"heavy"}}, nil, ErrInvalidAttribute},
+	}
+
+	for _, tt := range tests {
+		filters := []AttributeFilter{tt.filter}
+		err := ResolveAttributeFilters(chickenFields(), filters)
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("%s: err = %v, want %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if err == nil && !reflect.DeepEqual(filters[0].Values, tt.want) {
+			t.Errorf("%s: Values = %v, want %v", tt.name, filters[0].Values, tt.want)
+		}
+	}
+}
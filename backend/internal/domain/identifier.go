@@ -0,0 +1,63 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+)
+
+var (
+	ErrInvalidMicrochip = errors.New("microchip must be a 15 digit ISO 11784/11785 number")
+	ErrIdentifierInUse  = errors.New("microchip, leg band or collar tag already belongs to another animal, possibly one in the trash")
+	ErrNoIdentifier     = errors.New("no animal has this identifier")
+	ErrMissingLookup    = errors.New("chip is required")
+)
+
+// NormalizeMicrochip strips the spaces, dashes and dots scanners and
+// paperwork put in chip numbers.
+func NormalizeMicrochip(chip string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '-', '.':
+			return -1
+		}
+		return r
+	}, chip)
+}
+
+// ValidMicrochip checks a normalized chip number against ISO 11784/11785:
+// fifteen digits, the first three a country (001-899) or manufacturer
+// (900-998) code. 999 is reserved for test transponders.
+func ValidMicrochip(chip string) bool {
+	if len(chip) != 15 {
+		return false
+	}
+
+	for _, r := range chip {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+
+	code := chip[:3]
+	return code != "000" && code != "999"
+}
+
+// NormalizeTag trims a leg band or collar tag and upper-cases it, so "a12"
+// and "A12 " are the same band.
+func NormalizeTag(tag string) string {
+	return strings.ToUpper(strings.TrimSpace(tag))
+}
+
+// normalizeIdentifiers puts an animal's identifiers in their stored form
+// and validates the microchip.
+func (a *Animal) normalizeIdentifiers() error {
+	a.Microchip = NormalizeMicrochip(a.Microchip)
+	a.LegBand = NormalizeTag(a.LegBand)
+	a.CollarTag = NormalizeTag(a.CollarTag)
+
+	if a.Microchip != "" && !ValidMicrochip(a.Microchip) {
+		return ErrInvalidMicrochip
+	}
+
+	return nil
+}
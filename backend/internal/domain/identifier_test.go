@@ -0,0 +1,72 @@
+package domain
+
+import "testing"
+
+func TestValidMicrochip(t *testing.T) {
+	tests := []struct {
+		chip string
+		want bool
+	}{
+		{"985112003456789", true},
+		{"900164000123456", true},
+		{"040098100123456", true},
+		{"001000000000001", true},
+		{"98511200345678", false},
+		{"9851120034567890", false},
+		{"98511200345678A", false},
+		{"000112003456789", false},
+		{"999112003456789", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if got := ValidMicrochip(tt.chip); got != tt.want {
+			t.Errorf("ValidMicrochip(%q) = %v, want %v", tt.chip, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeIdentifiers(t *testing.T) {
+	tests := []struct {
+		name   string
+		animal Animal
+		want   Animal
+		err    error
+	}{
+		{
+			name:   "scanner formatting",
+			animal: Animal{Microchip: "985 112-003.456789", LegBand: " a12 ", CollarTag: "red-7"},
+			want:   Animal{Microchip: "985112003456789", LegBand: "A12", CollarTag: "RED-7"},
+		},
+		{
+			name:   "no identifiers",
+			animal: Animal{},
+			want:   Animal{},
+		},
+		{
+			name:   "short chip",
+			animal: Animal{Microchip: "985 112"},
+			err:    ErrInvalidMicrochip,
+		},
+		{
+			name:   "test transponder",
+			animal: Animal{Microchip: "999-000-000-000-001"},
+			err:    ErrInvalidMicrochip,
+		},
+	}
+
+	for _, tt := range tests {
+		animal := tt.animal
+		err := animal.normalizeIdentifiers()
+		if err != tt.err {
+			t.Errorf("%s: err = %v, want %v", tt.name, err, tt.err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if animal.Microchip != tt.want.Microchip || animal.LegBand != tt.want.LegBand || animal.CollarTag != tt.want.CollarTag {
+			t.Errorf("%s: got %q %q %q, want %q %q %q", tt.name, animal.Microchip, animal.LegBand, animal.CollarTag, tt.want.Microchip, tt.want.LegBand, tt.want.CollarTag)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+var ErrImageNotFound = errors.New("image not found")
+
+// ImageSet holds the resized variants generated for an upload. The full
+// variant doubles as Animal.ImageUrl.
+type ImageSet struct {
+	Thumbnail string `bson:"thumbnail,omitempty" json:"thumbnail,omitempty"`
+	Card      string `bson:"card,omitempty" json:"card,omitempty"`
+	Full      string `bson:"full,omitempty" json:"full,omitempty"`
+}
+
+func (s *ImageSet) URLs() []string {
+	var urls []string
+	for _, url := range []string{s.Thumbnail, s.Card, s.Full} {
+		if url != "" {
+			urls = append(urls, url)
+		}
+	}
+	return urls
+}
+
+type ImageInfo struct {
+	Key         string
+	ContentType string
+	Size        int64
+	ModTime     time.Time
+}
+
+// ImageStore keeps uploaded images. Keys are flat, store-relative names;
+// URL turns a key into the value stored on Animal.ImageUrl and Key reverses
+// it.
+type ImageStore interface {
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, *ImageInfo, error)
+	Stat(ctx context.Context, key string) (*ImageInfo, error)
+	List(ctx context.Context) ([]ImageInfo, error)
+	Delete(ctx context.Context, key string) error
+	URL(key string) string
+	Key(url string) string
+}
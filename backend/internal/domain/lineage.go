@@ -0,0 +1,199 @@
+package domain
+
+import (
+	"context"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	ErrAnimalNotFound  = errors.New("animal not found")
+	ErrLitterNotFound  = errors.New("litter not found")
+	ErrOwnParent       = errors.New("an animal can't be its own parent")
+	ErrSameParents     = errors.New("sire and dam must be different animals")
+	ErrParentCycle     = errors.New("parent is a descendant of the animal")
+	ErrParentSpecies   = errors.New("parents must be the same type of animal")
+	ErrSireSex         = errors.New("sire must not be female")
+	ErrDamSex          = errors.New("dam must not be male")
+	ErrLitterParents   = errors.New("sire and dam must match the animal's litter")
+	ErrInvalidDepth    = errors.New("depth must be between 1 and 10")
+	ErrMissingLitterOf = errors.New("litter needs a type")
+)
+
+const (
+	DefaultPedigreeDepth = 3
+	MaxPedigreeDepth     = 10
+)
+
+// SyncParents copies SireId and DamId into ParentIds, the single field the
+// lineage lookups walk.
+func (a *Animal) SyncParents() {
+	a.ParentIds = nil
+	if a.SireId != nil {
+		a.ParentIds = append(a.ParentIds, *a.SireId)
+	}
+	if a.DamId != nil {
+		a.ParentIds = append(a.ParentIds, *a.DamId)
+	}
+}
+
+// Litter groups siblings born or hatched together: a litter of puppies or
+// a clutch of chicks. Members point at it through Animal.LitterId and take
+// its parents and birth date.
+type Litter struct {
+	Id        primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	Name      string              `bson:"name,omitempty" json:"name,omitempty"`
+	Type      AnimalType          `bson:"type" json:"type"`
+	SireId    *primitive.ObjectID `bson:"sireId,omitempty" json:"sireId,omitempty"`
+	DamId     *primitive.ObjectID `bson:"damId,omitempty" json:"damId,omitempty"`
+	BirthDate primitive.DateTime  `bson:"birthDate,omitempty" json:"birthDate,omitempty"`
+	Notes     string              `bson:"notes,omitempty" json:"notes,omitempty"`
+	Members   []*Animal           `bson:"-" json:"members,omitempty"`
+}
+
+func (l *Litter) Validate() error {
+	if l.Type == 0 {
+		return ErrMissingLitterOf
+	}
+
+	if l.SireId != nil && l.DamId != nil && *l.SireId == *l.DamId {
+		return ErrSameParents
+	}
+
+	return nil
+}
+
+// ApplyTo fills in a member's parents and birth date from the litter, and
+// rejects parents that contradict it.
+func (l *Litter) ApplyTo(animal *Animal) error {
+	if animal.Type != l.Type {
+		return ErrParentSpecies
+	}
+
+	if animal.SireId == nil {
+		animal.SireId = l.SireId
+	} else if l.SireId == nil || *animal.SireId != *l.SireId {
+		return ErrLitterParents
+	}
+
+	if animal.DamId == nil {
+		animal.DamId = l.DamId
+	} else if l.DamId == nil || *animal.DamId != *l.DamId {
+		return ErrLitterParents
+	}
+
+	if animal.BirthDate == 0 {
+		animal.BirthDate = l.BirthDate
+	}
+
+	return nil
+}
+
+// CheckParent validates parent as the sire (or dam) of an animal of
+// animalType.
+func CheckParent(animalType AnimalType, parent *Animal, sire bool) error {
+	if parent.Type != animalType {
+		return ErrParentSpecies
+	}
+
+	if sire && parent.Sex == SexFemale {
+		return ErrSireSex
+	}
+
+	if !sire && parent.Sex == SexMale {
+		return ErrDamSex
+	}
+
+	return nil
+}
+
+// FamilyNode is one animal in a pedigree or descendants tree.
+type FamilyNode struct {
+	Id        primitive.ObjectID `json:"id"`
+	Name      string             `json:"name,omitempty"`
+	Type      AnimalType         `json:"type,omitempty"`
+	Breed     AnimalBreed        `json:"breed,omitempty"`
+	Sex       Sex                `json:"sex,omitempty"`
+	BirthDate primitive.DateTime `json:"birthDate,omitempty"`
+	Images    *ImageSet          `json:"images,omitempty"`
+	Sire      *FamilyNode        `json:"sire,omitempty"`
+	Dam       *FamilyNode        `json:"dam,omitempty"`
+	Children  []*FamilyNode      `json:"children,omitempty"`
+}
+
+func newFamilyNode(animal *Animal) *FamilyNode {
+	return &FamilyNode{
+		Id:        animal.Id,
+		Name:      animal.Name,
+		Type:      animal.Type,
+		Breed:     animal.Breed,
+		Sex:       animal.Sex,
+		BirthDate: animal.BirthDate,
+		Images:    animal.Images,
+	}
+}
+
+// Pedigree nests ancestors under root as sire and dam, depth generations
+// deep. Parents that aren't among ancestors are left out.
+func Pedigree(root *Animal, ancestors []*Animal, depth int) *FamilyNode {
+	byId := make(map[primitive.ObjectID]*Animal, len(ancestors))
+	for _, ancestor := range ancestors {
+		byId[ancestor.Id] = ancestor
+	}
+
+	var build func(animal *Animal, depth int) *FamilyNode
+	build = func(animal *Animal, depth int) *FamilyNode {
+		node := newFamilyNode(animal)
+		if depth == 0 {
+			return node
+		}
+		if animal.SireId != nil {
+			if sire, ok := byId[*animal.SireId]; ok {
+				node.Sire = build(sire, depth-1)
+			}
+		}
+		if animal.DamId != nil {
+			if dam, ok := byId[*animal.DamId]; ok {
+				node.Dam = build(dam, depth-1)
+			}
+		}
+		return node
+	}
+
+	return build(root, depth)
+}
+
+// Descendants nests descendants under root as children, depth generations
+// deep. An animal whose parents are both descendants shows up under each.
+func Descendants(root *Animal, descendants []*Animal, depth int) *FamilyNode {
+	children := make(map[primitive.ObjectID][]*Animal)
+	for _, descendant := range descendants {
+		for _, parentId := range descendant.ParentIds {
+			children[parentId] = append(children[parentId], descendant)
+		}
+	}
+
+	var build func(animal *Animal, depth int) *FamilyNode
+	build = func(animal *Animal, depth int) *FamilyNode {
+		node := newFamilyNode(animal)
+		if depth == 0 {
+			return node
+		}
+		for _, child := range children[animal.Id] {
+			node.Children = append(node.Children, build(child, depth-1))
+		}
+		return node
+	}
+
+	return build(root, depth)
+}
+
+type LitterRepository interface {
+	List(ctx context.Context, animalType AnimalType) ([]*Litter, error)
+	GetById(ctx context.Context, id string) (*Litter, error)
+	Insert(ctx context.Context, litter Litter) (*Litter, error)
+	Update(ctx context.Context, id string, litter Litter) error
+	Delete(ctx context.Context, id string) error
+	RemoveParent(ctx context.Context, parentId primitive.ObjectID) error
+}
@@ -0,0 +1,18 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var ErrLockHeld = errors.New("lock is held by another replica")
+
+// LockRepository hands out leases so that jobs scheduled on every replica
+// run on only one of them.
+type LockRepository interface {
+	// Acquire takes name until expiry, failing with ErrLockHeld while
+	// another lease on it is live.
+	Acquire(ctx context.Context, name string, expiry time.Time) error
+	Release(ctx context.Context, name string) error
+}
@@ -0,0 +1,197 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	ErrMedicalRecordNotFound = errors.New("medical record not found")
+	ErrInvalidMedicalKind    = errors.New("kind must be visit, diagnosis or medication")
+	ErrMissingMedicalDate    = errors.New("medical record needs a date")
+	ErrMissingClinic         = errors.New("vet visit needs a clinic or reason")
+	ErrInvalidCost           = errors.New("cost must not be negative")
+	ErrMissingCondition      = errors.New("diagnosis needs a condition")
+	ErrMissingDrug           = errors.New("medication needs a drug")
+	ErrInvalidFrequency      = errors.New("medication needs 1-24 doses a day every 1 or more days, or as needed")
+	ErrInvalidCourseEnd      = errors.New("medication end date must not be before its start")
+)
+
+type MedicalKind string
+
+const (
+	MedicalVisit      MedicalKind = "visit"
+	MedicalDiagnosis  MedicalKind = "diagnosis"
+	MedicalMedication MedicalKind = "medication"
+	// MedicalVaccination only appears in timelines, vaccinations are stored
+	// on the animal.
+	MedicalVaccination MedicalKind = "vaccination"
+)
+
+func (k MedicalKind) Valid() bool {
+	return k == MedicalVisit || k == MedicalDiagnosis || k == MedicalMedication
+}
+
+// Frequency is how often a medication is given: TimesPerDay doses on every
+// EveryDays-th day of the course, or only when needed. Times optionally
+// pins the doses to "15:04" times of day.
+type Frequency struct {
+	TimesPerDay int      `bson:"timesPerDay,omitempty" json:"timesPerDay,omitempty"`
+	EveryDays   int      `bson:"everyDays,omitempty" json:"everyDays,omitempty"`
+	Times       []string `bson:"times,omitempty" json:"times,omitempty"`
+	AsNeeded    bool     `bson:"asNeeded,omitempty" json:"asNeeded,omitempty"`
+}
+
+func (f *Frequency) Validate() error {
+	if f.AsNeeded {
+		return nil
+	}
+
+	if f.EveryDays == 0 {
+		f.EveryDays = 1
+	}
+
+	if len(f.Times) > 0 {
+		if _, err := f.TimesOfDay(); err != nil {
+			return err
+		}
+		f.TimesPerDay = len(f.Times)
+	}
+
+	if f.TimesPerDay < 1 || f.TimesPerDay > 24 || f.EveryDays < 1 {
+		return ErrInvalidFrequency
+	}
+
+	return nil
+}
+
+// MedicalRecord is one entry in an animal's medical history. Which fields
+// apply depends on Kind; for medications Date is the start of the course
+// and EndDate, when set, its last day. Diagnoses and medications can point
+// at the visit they came out of through VisitId.
+type MedicalRecord struct {
+	Id         primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	AnimalId   primitive.ObjectID  `bson:"animalId" json:"animalId"`
+	Kind       MedicalKind         `bson:"kind" json:"kind"`
+	Date       primitive.DateTime  `bson:"date" json:"date"`
+	VisitId    *primitive.ObjectID `bson:"visitId,omitempty" json:"visitId,omitempty"`
+	Notes      string              `bson:"notes,omitempty" json:"notes,omitempty"`
+	RecordedBy string              `bson:"recordedBy,omitempty" json:"recordedBy,omitempty"`
+
+	Clinic string  `bson:"clinic,omitempty" json:"clinic,omitempty"`
+	Reason string  `bson:"reason,omitempty" json:"reason,omitempty"`
+	Cost   float64 `bson:"cost,omitempty" json:"cost,omitempty"`
+
+	Condition string `bson:"condition,omitempty" json:"condition,omitempty"`
+
+	Drug      string              `bson:"drug,omitempty" json:"drug,omitempty"`
+	Dose      string              `bson:"dose,omitempty" json:"dose,omitempty"`
+	Frequency *Frequency          `bson:"frequency,omitempty" json:"frequency,omitempty"`
+	EndDate   *primitive.DateTime `bson:"endDate,omitempty" json:"endDate,omitempty"`
+}
+
+func (m *MedicalRecord) Validate() error {
+	if !m.Kind.Valid() {
+		return ErrInvalidMedicalKind
+	}
+
+	if m.Date == 0 {
+		return ErrMissingMedicalDate
+	}
+
+	switch m.Kind {
+	case MedicalVisit:
+		if m.Clinic == "" && m.Reason == "" {
+			return ErrMissingClinic
+		}
+		if m.Cost < 0 {
+			return ErrInvalidCost
+		}
+	case MedicalDiagnosis:
+		if m.Condition == "" {
+			return ErrMissingCondition
+		}
+	case MedicalMedication:
+		if m.Drug == "" {
+			return ErrMissingDrug
+		}
+		if m.Frequency == nil {
+			return ErrInvalidFrequency
+		}
+		if err := m.Frequency.Validate(); err != nil {
+			return err
+		}
+		if m.EndDate != nil && *m.EndDate < m.Date {
+			return ErrInvalidCourseEnd
+		}
+	}
+
+	return nil
+}
+
+// Active reports whether a medication course runs on the day of t. EndDate
+// is the last day of the course, so it stays active until the day after.
+func (m *MedicalRecord) Active(t time.Time) bool {
+	if m.Kind != MedicalMedication || m.Date.Time().After(t) {
+		return false
+	}
+	return m.EndDate == nil || t.Before(m.EndDate.Time().AddDate(0, 0, 1))
+}
+
+// ActiveMedication is a medication course together with the animal it is
+// for.
+type ActiveMedication struct {
+	AnimalId   primitive.ObjectID `bson:"animalId" json:"animalId"`
+	AnimalName string             `bson:"animalName" json:"animalName"`
+	AnimalType AnimalType         `bson:"animalType" json:"animalType"`
+	Medication MedicalRecord      `bson:"medication" json:"medication"`
+}
+
+// TimelineEntry is one event in an animal's medical timeline: either a
+// stored record or one of the vaccinations kept on the animal.
+type TimelineEntry struct {
+	Date        primitive.DateTime `json:"date"`
+	Kind        MedicalKind        `json:"kind"`
+	Record      *MedicalRecord     `json:"record,omitempty"`
+	Vaccination *Vaccination       `json:"vaccination,omitempty"`
+}
+
+// MedicalTimeline merges records with the animal's given vaccinations,
+// newest first.
+func MedicalTimeline(animal *Animal, records []*MedicalRecord) []TimelineEntry {
+	timeline := make([]TimelineEntry, 0, len(records)+len(animal.Vaccinations))
+
+	for _, record := range records {
+		timeline = append(timeline, TimelineEntry{Date: record.Date, Kind: record.Kind, Record: record})
+	}
+
+	for i := range animal.Vaccinations {
+		vaccination := &animal.Vaccinations[i]
+		if vaccination.DateGiven == 0 {
+			continue
+		}
+		timeline = append(timeline, TimelineEntry{Date: vaccination.DateGiven, Kind: MedicalVaccination, Vaccination: vaccination})
+	}
+
+	sort.SliceStable(timeline, func(i, j int) bool {
+		return timeline[i].Date > timeline[j].Date
+	})
+
+	return timeline
+}
+
+type MedicalRepository interface {
+	ForAnimal(ctx context.Context, animalId primitive.ObjectID) ([]*MedicalRecord, error)
+	GetById(ctx context.Context, id string) (*MedicalRecord, error)
+	Insert(ctx context.Context, record MedicalRecord) (*MedicalRecord, error)
+	Update(ctx context.Context, id string, record MedicalRecord) error
+	Delete(ctx context.Context, id string) error
+	DeleteForAnimal(ctx context.Context, animalId primitive.ObjectID) error
+	UnlinkVisit(ctx context.Context, visitId primitive.ObjectID) error
+	ActiveMedications(ctx context.Context, from, to time.Time, types []AnimalType) ([]*ActiveMedication, error)
+	AnimalsOnMedication(ctx context.Context, at time.Time) ([]primitive.ObjectID, error)
+}
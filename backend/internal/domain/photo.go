@@ -0,0 +1,74 @@
+package domain
+
+import (
+	"errors"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	ErrPhotoNotFound = errors.New("photo not found")
+	ErrPhotoOrder    = errors.New("photo order must list every photo exactly once")
+)
+
+type Photo struct {
+	Id         primitive.ObjectID `bson:"id" json:"id"`
+	Url        string             `bson:"url" json:"url"`
+	Images     *ImageSet          `bson:"images,omitempty" json:"images,omitempty"`
+	Caption    string             `bson:"caption,omitempty" json:"caption,omitempty"`
+	TakenAt    primitive.DateTime `bson:"takenAt,omitempty" json:"takenAt,omitempty"`
+	UploadedBy string             `bson:"uploadedBy,omitempty" json:"uploadedBy,omitempty"`
+	UploadedAt primitive.DateTime `bson:"uploadedAt,omitempty" json:"uploadedAt,omitempty"`
+	IsPrimary  bool               `bson:"isPrimary" json:"isPrimary"`
+}
+
+func (p *Photo) URLs() []string {
+	urls := []string{p.Url}
+	if p.Images != nil {
+		urls = append(urls, p.Images.URLs()...)
+	}
+	return urls
+}
+
+// MaterializeLegacyPhoto turns an ImageUrl uploaded before galleries
+// existed into the animal's first, primary photo.
+func (a *Animal) MaterializeLegacyPhoto() {
+	if len(a.Photos) > 0 || a.ImageUrl == "" {
+		return
+	}
+
+	a.Photos = []Photo{{
+		Id:        primitive.NewObjectID(),
+		Url:       a.ImageUrl,
+		Images:    a.Images,
+		IsPrimary: true,
+	}}
+}
+
+func (a *Animal) Photo(id primitive.ObjectID) *Photo {
+	for i := range a.Photos {
+		if a.Photos[i].Id == id {
+			return &a.Photos[i]
+		}
+	}
+	return nil
+}
+
+// ValidatePhotoOrder checks that ids lists every one of the animal's photos
+// exactly once.
+func (a *Animal) ValidatePhotoOrder(ids []primitive.ObjectID) error {
+	if len(ids) != len(a.Photos) {
+		return ErrPhotoOrder
+	}
+
+	seen := map[primitive.ObjectID]bool{}
+
+	for _, id := range ids {
+		if a.Photo(id) == nil || seen[id] {
+			return ErrPhotoOrder
+		}
+		seen[id] = true
+	}
+
+	return nil
+}
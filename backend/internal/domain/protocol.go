@@ -0,0 +1,118 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	ErrProtocolNotFound  = errors.New("protocol not found")
+	ErrInvalidProtocolId = errors.New("invalid protocol id")
+)
+
+// VaccinationProtocol describes how often a vaccine is given to a species,
+// optionally narrowed to one breed. An IntervalDays of zero means the
+// vaccine is given once and never boosted.
+type VaccinationProtocol struct {
+	Id           primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	Name         string             `bson:"name" json:"name"`
+	Description  string             `bson:"description,omitempty" json:"description,omitempty"`
+	Type         AnimalType         `bson:"type" json:"type"`
+	Breed        AnimalBreed        `bson:"breed,omitempty" json:"breed,omitempty"`
+	IntervalDays int                `bson:"intervalDays" json:"intervalDays"`
+}
+
+func (p *VaccinationProtocol) Validate() error {
+	if strings.TrimSpace(p.Name) == "" {
+		return errors.New("protocol name is required")
+	}
+
+	if p.Type == 0 {
+		return errors.New("protocol type is required")
+	}
+
+	if p.IntervalDays < 0 {
+		return errors.New("protocol interval cannot be negative")
+	}
+
+	return nil
+}
+
+func (p *VaccinationProtocol) Applies(animal *Animal) bool {
+	return p.Type == animal.Type && (p.Breed == 0 || p.Breed == animal.Breed)
+}
+
+func (p *VaccinationProtocol) Matches(vaccination Vaccination) bool {
+	return strings.EqualFold(strings.TrimSpace(p.Name), strings.TrimSpace(vaccination.Name))
+}
+
+// NextDose returns the booster that follows a given dose.
+func (p *VaccinationProtocol) NextDose(given Vaccination) (Vaccination, bool) {
+	if p.IntervalDays == 0 || given.DateGiven == 0 {
+		return Vaccination{}, false
+	}
+
+	next := given.DateGiven.Time().AddDate(0, 0, p.IntervalDays)
+
+	return Vaccination{Name: p.Name, DateNeeded: primitive.NewDateTimeFromTime(next)}, true
+}
+
+// Backfill returns the pending vaccination an existing animal should carry
+// under this protocol, if it doesn't have one yet.
+func (p *VaccinationProtocol) Backfill(animal *Animal, now time.Time) (Vaccination, bool) {
+	var latest *Vaccination
+
+	for i, v := range animal.Vaccinations {
+		if !p.Matches(v) {
+			continue
+		}
+
+		if v.DateGiven == 0 {
+			return Vaccination{}, false
+		}
+
+		if latest == nil || v.DateGiven > latest.DateGiven {
+			latest = &animal.Vaccinations[i]
+		}
+	}
+
+	if latest == nil {
+		return Vaccination{Name: p.Name, DateNeeded: primitive.NewDateTimeFromTime(now)}, true
+	}
+
+	if latest.DateNeeded != 0 {
+		return Vaccination{}, false
+	}
+
+	return p.NextDose(*latest)
+}
+
+// ProtocolFor picks the most specific protocol for a vaccination, preferring
+// a breed protocol over one covering the whole species.
+func ProtocolFor(protocols []*VaccinationProtocol, animal *Animal, vaccination Vaccination) *VaccinationProtocol {
+	var match *VaccinationProtocol
+
+	for _, p := range protocols {
+		if !p.Applies(animal) || !p.Matches(vaccination) {
+			continue
+		}
+
+		if match == nil || (match.Breed == 0 && p.Breed != 0) {
+			match = p
+		}
+	}
+
+	return match
+}
+
+type ProtocolRepository interface {
+	GetAll(ctx context.Context, animalType AnimalType) ([]*VaccinationProtocol, error)
+	GetById(ctx context.Context, id string) (*VaccinationProtocol, error)
+	Insert(ctx context.Context, protocol VaccinationProtocol) (*VaccinationProtocol, error)
+	Update(ctx context.Context, id string, protocol VaccinationProtocol) error
+	Delete(ctx context.Context, id string) error
+}
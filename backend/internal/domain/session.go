@@ -1,43 +1,46 @@
 package domain
 
 import (
+	"context"
 	"crypto/rand"
 	"encoding/base64"
+	"errors"
 	"io"
 	"time"
 )
 
+const SessionDuration = time.Hour
+
+var ErrSessionNotFound = errors.New("session not found")
+
 type Session struct {
-	Username string
-	Expiry   time.Time
+	Id       string    `bson:"_id"`
+	Username string    `bson:"username"`
+	Role     Role      `bson:"role"`
+	Expiry   time.Time `bson:"expiry"`
 }
 
-var sessions = make(map[string]Session)
-
 func (s *Session) IsExpired() bool {
 	return s.Expiry.Before(time.Now())
 }
 
-func GetSessions() map[string]Session {
-	return sessions
-}
-
-func SetSession(session Session) string {
-	sessionId := sessionId()
-
-	sessions[sessionId] = session
-
-	return sessionId
+type SessionStore interface {
+	Create(ctx context.Context, session Session) (string, error)
+	Get(ctx context.Context, sessionId string) (*Session, error)
+	Touch(ctx context.Context, sessionId string, expiry time.Time) error
+	Delete(ctx context.Context, sessionId string) error
+	DeleteForUser(ctx context.Context, username string) error
+	DeleteExpired(ctx context.Context) (int64, error)
 }
 
-func RemoveSession(sessionId string) {
-	delete(sessions, sessionId)
+func NewSessionId() (string, error) {
+	return randomToken()
 }
 
-func sessionId() string {
+func randomToken() (string, error) {
 	b := make([]byte, 32)
 	if _, err := io.ReadFull(rand.Reader, b); err != nil {
-		return ""
+		return "", err
 	}
-	return base64.URLEncoding.EncodeToString(b)
+	return base64.URLEncoding.EncodeToString(b), nil
 }
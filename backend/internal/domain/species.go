@@ -0,0 +1,67 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"regexp"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	ErrSpeciesNotFound = errors.New("animal type not found")
+	ErrSpeciesExists   = errors.New("an animal type with this id or slug already exists")
+	ErrSpeciesInUse    = errors.New("animal type is still used by animals or breeds")
+	ErrMissingSpecies  = errors.New("animal type needs a name and a plural")
+	ErrInvalidSlug     = errors.New("slug must be lowercase letters, digits and dashes")
+	ErrUnknownType     = errors.New("unknown animal type")
+	ErrSpeciesDisabled = errors.New("animal type is disabled")
+	ErrSpeciesProtocol = errors.New("default protocol must exist and be for the same animal type")
+)
+
+var slugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// Species is an entry in the animal type registry. Its Id is the AnimalType
+// stored on animals, breeds and protocols; Slug names it in URLs such as
+// /api/types/{slug}/animals. Types that aren't Enabled keep their animals
+// but take no new ones. New animals get the first dose of ProtocolId, if
+// set, and eggs can be logged for animals of types that LaysEggs.
+type Species struct {
+	Id         AnimalType          `bson:"_id" json:"id"`
+	Slug       string              `bson:"slug" json:"slug"`
+	Name       string              `bson:"name" json:"name"`
+	Plural     string              `bson:"plural" json:"plural"`
+	Icon       string              `bson:"icon,omitempty" json:"icon,omitempty"`
+	Enabled    bool                `bson:"enabled" json:"enabled"`
+	LaysEggs   bool                `bson:"laysEggs,omitempty" json:"laysEggs,omitempty"`
+	ProtocolId *primitive.ObjectID `bson:"protocolId,omitempty" json:"protocolId,omitempty"`
+}
+
+func (s *Species) Validate() error {
+	if s.Name == "" || s.Plural == "" {
+		return ErrMissingSpecies
+	}
+
+	if !slugPattern.MatchString(s.Slug) {
+		return ErrInvalidSlug
+	}
+
+	return nil
+}
+
+// DefaultSpecies seeds an empty registry with the types the app started
+// out with. Their slugs match the old /api/cats style routes.
+var DefaultSpecies = []Species{
+	{Id: CatType, Slug: "cats", Name: "Cat", Plural: "Cats", Icon: "pets", Enabled: true},
+	{Id: ChickenType, Slug: "chickens", Name: "Chicken", Plural: "Chickens", Icon: "egg", Enabled: true, LaysEggs: true},
+	{Id: DogType, Slug: "dogs", Name: "Dog", Plural: "Dogs", Icon: "pets", Enabled: true},
+}
+
+type SpeciesRepository interface {
+	List(ctx context.Context, includeDisabled bool) ([]*Species, error)
+	GetById(ctx context.Context, id AnimalType) (*Species, error)
+	GetBySlug(ctx context.Context, slug string) (*Species, error)
+	Insert(ctx context.Context, species Species) (*Species, error)
+	Update(ctx context.Context, id AnimalType, species Species) error
+	Delete(ctx context.Context, id AnimalType) error
+}
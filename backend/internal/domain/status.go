@@ -0,0 +1,143 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	ErrInvalidStatus    = errors.New("status must be intake, available, onHold, adopted, fostered, deceased or sold")
+	ErrStatusTransition = errors.New("status change not allowed")
+	ErrStatusChanged    = errors.New("status was changed by someone else, reload and try again")
+	ErrContactRequired  = errors.New("adopted, fostered and sold animals need a contact")
+)
+
+// Status is where an animal is in its life with us. Animals saved before
+// statuses existed have none and count as available.
+type Status string
+
+const (
+	StatusIntake    Status = "intake"
+	StatusAvailable Status = "available"
+	StatusOnHold    Status = "onHold"
+	StatusAdopted   Status = "adopted"
+	StatusFostered  Status = "fostered"
+	StatusDeceased  Status = "deceased"
+	StatusSold      Status = "sold"
+)
+
+// statusTransitions lists the moves allowed out of each status. Adopted and
+// sold animals can only come back through intake; deceased is final.
+var statusTransitions = map[Status][]Status{
+	StatusIntake:    {StatusAvailable, StatusOnHold, StatusFostered, StatusDeceased},
+	StatusAvailable: {StatusOnHold, StatusAdopted, StatusFostered, StatusSold, StatusDeceased},
+	StatusOnHold:    {StatusAvailable, StatusAdopted, StatusFostered, StatusSold, StatusDeceased},
+	StatusFostered:  {StatusAvailable, StatusOnHold, StatusAdopted, StatusDeceased},
+	StatusAdopted:   {StatusIntake},
+	StatusSold:      {StatusIntake},
+	StatusDeceased:  {},
+}
+
+// ActiveStatuses are those of the animals still in our care. Due lists and
+// dose and feeding schedules leave the others out.
+var ActiveStatuses = []Status{StatusIntake, StatusAvailable, StatusOnHold, StatusFostered}
+
+func (s Status) Valid() bool {
+	_, ok := statusTransitions[s]
+	return ok
+}
+
+// CanBecome reports whether an animal in status s may move to status to.
+func (s Status) CanBecome(to Status) bool {
+	for _, next := range statusTransitions[s] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Active reports whether an animal in status s is still in our care.
+func (s Status) Active() bool {
+	for _, active := range ActiveStatuses {
+		if s == active {
+			return true
+		}
+	}
+	return false
+}
+
+// NeedsContact reports whether the status names who has the animal: its
+// adopter, foster or buyer.
+func (s Status) NeedsContact() bool {
+	return s == StatusAdopted || s == StatusFostered || s == StatusSold
+}
+
+// StatusChange is one entry in an animal's status history.
+type StatusChange struct {
+	Status    Status              `bson:"status" json:"status"`
+	From      Status              `bson:"from,omitempty" json:"from,omitempty"`
+	At        time.Time           `bson:"at" json:"at"`
+	By        string              `bson:"by,omitempty" json:"by,omitempty"`
+	ContactId *primitive.ObjectID `bson:"contactId,omitempty" json:"contactId,omitempty"`
+	Note      string              `bson:"note,omitempty" json:"note,omitempty"`
+}
+
+// CurrentStatus is the animal's status, counting animals without one as
+// available.
+func (a *Animal) CurrentStatus() Status {
+	if a.Status == "" {
+		return StatusAvailable
+	}
+	return a.Status
+}
+
+// ChangeStatus checks change against the state machine and the contact
+// rules, filling in its From.
+func (a *Animal) ChangeStatus(change *StatusChange) error {
+	if !change.Status.Valid() {
+		return ErrInvalidStatus
+	}
+
+	from := a.CurrentStatus()
+	if !from.CanBecome(change.Status) {
+		return fmt.Errorf("%w: %s to %s", ErrStatusTransition, from, change.Status)
+	}
+
+	if change.Status.NeedsContact() && change.ContactId == nil {
+		return ErrContactRequired
+	}
+	if !change.Status.NeedsContact() {
+		change.ContactId = nil
+	}
+
+	change.From = from
+
+	return nil
+}
+
+// StartStatus gives a new animal its first status, intake unless it came
+// with one, and opens its history.
+func (a *Animal) StartStatus(at time.Time, by string) error {
+	if a.Status == "" {
+		a.Status = StatusIntake
+	}
+
+	if !a.Status.Valid() {
+		return ErrInvalidStatus
+	}
+
+	if a.Status.NeedsContact() && a.ContactId == nil {
+		return ErrContactRequired
+	}
+	if !a.Status.NeedsContact() {
+		a.ContactId = nil
+	}
+
+	a.StatusHistory = []StatusChange{{Status: a.Status, At: at, By: by, ContactId: a.ContactId}}
+
+	return nil
+}
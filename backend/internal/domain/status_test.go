@@ -0,0 +1,167 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestStatusTransitions(t *testing.T) {
+	all := []Status{StatusIntake, StatusAvailable, StatusOnHold, StatusAdopted, StatusFostered, StatusDeceased, StatusSold}
+
+	allowed := map[Status]map[Status]bool{
+		StatusIntake:    {StatusAvailable: true, StatusOnHold: true, StatusFostered: true, StatusDeceased: true},
+		StatusAvailable: {StatusOnHold: true, StatusAdopted: true, StatusFostered: true, StatusSold: true, StatusDeceased: true},
+		StatusOnHold:    {StatusAvailable: true, StatusAdopted: true, StatusFostered: true, StatusSold: true, StatusDeceased: true},
+		StatusFostered:  {StatusAvailable: true, StatusOnHold: true, StatusAdopted: true, StatusDeceased: true},
+		StatusAdopted:   {StatusIntake: true},
+		StatusSold:      {StatusIntake: true},
+		StatusDeceased:  {},
+	}
+
+	for _, from := range all {
+		if !from.Valid() {
+			t.Errorf("%s is not Valid", from)
+		}
+		for _, to := range all {
+			if got := from.CanBecome(to); got != allowed[from][to] {
+				t.Errorf("%s.CanBecome(%s) = %v, want %v", from, to, got, allowed[from][to])
+			}
+		}
+	}
+
+	for _, s := range []Status{"", "lost", "Available"} {
+		if s.Valid() {
+			t.Errorf("%q is Valid", s)
+		}
+	}
+}
+
+func TestStatusActive(t *testing.T) {
+	tests := []struct {
+		status Status
+		want   bool
+	}{
+		{StatusIntake, true},
+		{StatusAvailable, true},
+		{StatusOnHold, true},
+		{StatusFostered, true},
+		{StatusAdopted, false},
+		{StatusSold, false},
+		{StatusDeceased, false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.status.Active(); got != tt.want {
+			t.Errorf("%s.Active() = %v, want %v", tt.status, got, tt.want)
+		}
+	}
+
+	var legacy Animal
+	if !legacy.CurrentStatus().Active() {
+		t.Error("an animal without a status should count as active")
+	}
+}
+
+func TestChangeStatus(t *testing.T) {
+	contact := primitive.NewObjectID()
+
+	tests := []struct {
+		name    string
+		from    Status
+		change  StatusChange
+		wantErr error
+		want    StatusChange
+	}{
+		{
+			name:   "legacy animal put on hold",
+			from:   "",
+			change: StatusChange{Status: StatusOnHold},
+			want:   StatusChange{Status: StatusOnHold, From: StatusAvailable},
+		},
+		{
+			name:   "adopted with a contact",
+			from:   StatusAvailable,
+			change: StatusChange{Status: StatusAdopted, ContactId: &contact},
+			want:   StatusChange{Status: StatusAdopted, From: StatusAvailable, ContactId: &contact},
+		},
+		{
+			name:   "contact dropped when not needed",
+			from:   StatusAvailable,
+			change: StatusChange{Status: StatusOnHold, ContactId: &contact},
+			want:   StatusChange{Status: StatusOnHold, From: StatusAvailable},
+		},
+		{
+			name:    "adopted without a contact",
+			from:    StatusAvailable,
+			change:  StatusChange{Status: StatusAdopted},
+			wantErr: ErrContactRequired,
+		},
+		{
+			name:    "deceased is final",
+			from:    StatusDeceased,
+			change:  StatusChange{Status: StatusAvailable},
+			wantErr: ErrStatusTransition,
+		},
+		{
+			name:    "adopted comes back through intake",
+			from:    StatusAdopted,
+			change:  StatusChange{Status: StatusAvailable},
+			wantErr: ErrStatusTransition,
+		},
+		{
+			name:    "unknown status",
+			from:    StatusAvailable,
+			change:  StatusChange{Status: "lost"},
+			wantErr: ErrInvalidStatus,
+		},
+	}
+
+	for _, tt := range tests {
+		animal := Animal{Status: tt.from}
+		change := tt.change
+
+		err := animal.ChangeStatus(&change)
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("%s: err = %v, want %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+
+		if change.Status != tt.want.Status || change.From != tt.want.From || (change.ContactId == nil) != (tt.want.ContactId == nil) {
+			t.Errorf("%s: change = %+v, want %+v", tt.name, change, tt.want)
+		}
+	}
+}
+
+func TestStartStatus(t *testing.T) {
+	at := time.Date(2024, time.March, 5, 9, 0, 0, 0, time.UTC)
+	contact := primitive.NewObjectID()
+
+	var animal Animal
+	if err := animal.StartStatus(at, "sam"); err != nil {
+		t.Fatal(err)
+	}
+	if animal.Status != StatusIntake || len(animal.StatusHistory) != 1 || animal.StatusHistory[0].By != "sam" || !animal.StatusHistory[0].At.Equal(at) {
+		t.Errorf("new animal = %s %+v, want intake with one history entry", animal.Status, animal.StatusHistory)
+	}
+
+	fostered := Animal{Status: StatusFostered, ContactId: &contact}
+	if err := fostered.StartStatus(at, "sam"); err != nil || fostered.StatusHistory[0].ContactId == nil {
+		t.Errorf("fostered animal: err = %v, history = %+v", err, fostered.StatusHistory)
+	}
+
+	sold := Animal{Status: StatusSold}
+	if err := sold.StartStatus(at, "sam"); err != ErrContactRequired {
+		t.Errorf("sold without a contact: err = %v, want %v", err, ErrContactRequired)
+	}
+
+	unknown := Animal{Status: "lost"}
+	if err := unknown.StartStatus(at, "sam"); err != ErrInvalidStatus {
+		t.Errorf("unknown status: err = %v, want %v", err, ErrInvalidStatus)
+	}
+}
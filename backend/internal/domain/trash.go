@@ -0,0 +1,12 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrNotInTrash = errors.New("animal is not in the trash")
+
+// DefaultTrashRetention is how long deleted animals stay in the trash
+// before they and their images are purged.
+const DefaultTrashRetention = 30 * 24 * time.Hour
@@ -1,12 +1,110 @@
 package domain
 
-import "context"
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const MinPasswordLength = 8
+
+var (
+	ErrUserNotFound    = errors.New("user not found")
+	ErrUserExists      = errors.New("user already exists")
+	ErrInvalidRole     = errors.New("invalid role")
+	ErrLastAdmin       = errors.New("cannot remove the last active admin")
+	ErrPasswordTooWeak = errors.New("password must be at least 8 characters")
+)
+
+type Role string
+
+const (
+	RoleAdmin     Role = "admin"
+	RoleCaretaker Role = "caretaker"
+	RoleViewer    Role = "viewer"
+)
+
+func (r Role) Valid() bool {
+	switch r {
+	case RoleAdmin, RoleCaretaker, RoleViewer:
+		return true
+	}
+	return false
+}
+
+func (r Role) CanWrite() bool {
+	return r == RoleAdmin || r == RoleCaretaker
+}
+
+func (r Role) CanManageUsers() bool {
+	return r == RoleAdmin
+}
 
 type User struct {
-	Username string
-	Password string
+	Id       primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	Username string             `bson:"username" json:"username"`
+	Password string             `bson:"password" json:"-"`
+	Role     Role               `bson:"role" json:"role"`
+	Disabled bool               `bson:"disabled" json:"disabled"`
+	// CalendarToken holds the SHA-256 of the user's calendar feed token.
+	CalendarToken string    `bson:"calendarToken,omitempty" json:"-"`
+	CreatedAt     time.Time `bson:"createdAt,omitempty" json:"createdAt,omitempty"`
+}
+
+type UserUpdate struct {
+	Role     *Role `json:"role,omitempty"`
+	Disabled *bool `json:"disabled,omitempty"`
+}
+
+func ValidatePassword(password string) error {
+	if len(password) < MinPasswordLength {
+		return ErrPasswordTooWeak
+	}
+	return nil
+}
+
+func HashPassword(password string) (string, error) {
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+
+	return string(hashed), nil
+}
+
+// NewCalendarToken returns a feed token for calendar clients, which cannot
+// send the session cookie, together with the hash that gets stored.
+func NewCalendarToken() (string, string, error) {
+	token, err := randomToken()
+	if err != nil {
+		return "", "", err
+	}
+
+	return token, HashToken(token), nil
+}
+
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func (u *User) CheckPassword(password string) error {
+	return bcrypt.CompareHashAndPassword([]byte(u.Password), []byte(password))
 }
 
 type UserRepository interface {
 	GetUser(ctx context.Context, username string) (*User, error)
+	List(ctx context.Context) ([]*User, error)
+	Insert(ctx context.Context, user User) (*User, error)
+	Update(ctx context.Context, username string, update UserUpdate) error
+	SetPassword(ctx context.Context, username string, hashedPassword string) error
+	Delete(ctx context.Context, username string) error
+	CountActiveAdmins(ctx context.Context) (int64, error)
+	GetByCalendarToken(ctx context.Context, tokenHash string) (*User, error)
+	SetCalendarToken(ctx context.Context, username string, tokenHash string) error
 }
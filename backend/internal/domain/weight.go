@@ -0,0 +1,179 @@
+package domain
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const poundsPerKilogram = 1 / 0.45359237
+
+var (
+	ErrInvalidWeight        = errors.New("weight must be a positive number")
+	ErrInvalidWeightUnit    = errors.New("weight unit must be kg or lb")
+	ErrInvalidBodyCondition = errors.New("body condition score must be between 1 and 9")
+	ErrInvalidInterval      = errors.New("interval must be day, week or month")
+)
+
+type WeightUnit string
+
+const (
+	Kilograms WeightUnit = "kg"
+	Pounds    WeightUnit = "lb"
+)
+
+func (u WeightUnit) Valid() bool {
+	return u == Kilograms || u == Pounds
+}
+
+// Weight is one weigh-in. BodyCondition is the 1-9 body condition score,
+// left at zero when it wasn't assessed.
+type Weight struct {
+	Id            primitive.ObjectID `bson:"id" json:"id"`
+	Value         float64            `bson:"value" json:"value"`
+	Unit          WeightUnit         `bson:"unit" json:"unit"`
+	Date          primitive.DateTime `bson:"date" json:"date"`
+	BodyCondition int                `bson:"bodyCondition,omitempty" json:"bodyCondition,omitempty"`
+	Note          string             `bson:"note,omitempty" json:"note,omitempty"`
+}
+
+func (w *Weight) Validate() error {
+	if w.Value <= 0 || math.IsNaN(w.Value) || math.IsInf(w.Value, 0) {
+		return ErrInvalidWeight
+	}
+
+	if !w.Unit.Valid() {
+		return ErrInvalidWeightUnit
+	}
+
+	if w.BodyCondition < 0 || w.BodyCondition > 9 {
+		return ErrInvalidBodyCondition
+	}
+
+	return nil
+}
+
+func (w *Weight) In(unit WeightUnit) float64 {
+	return ConvertWeight(w.Value, w.Unit, unit)
+}
+
+func ConvertWeight(value float64, from, to WeightUnit) float64 {
+	switch {
+	case from == to:
+		return value
+	case from == Kilograms && to == Pounds:
+		return value * poundsPerKilogram
+	case from == Pounds && to == Kilograms:
+		return value / poundsPerKilogram
+	}
+	return value
+}
+
+type WeightInterval string
+
+const (
+	WeightRaw   WeightInterval = ""
+	WeightDay   WeightInterval = "day"
+	WeightWeek  WeightInterval = "week"
+	WeightMonth WeightInterval = "month"
+)
+
+func (i WeightInterval) Valid() bool {
+	switch i {
+	case WeightRaw, WeightDay, WeightWeek, WeightMonth:
+		return true
+	}
+	return false
+}
+
+// start returns the beginning of the bucket t falls in. Weeks start on
+// Monday.
+func (i WeightInterval) start(t time.Time) time.Time {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+
+	switch i {
+	case WeightWeek:
+		offset := (int(day.Weekday()) + 6) % 7
+		return day.AddDate(0, 0, -offset)
+	case WeightMonth:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+	}
+
+	return day
+}
+
+// WeightPoint is one point of a weight series. For downsampled series it
+// averages every weigh-in in the bucket starting at Date.
+type WeightPoint struct {
+	Date          time.Time  `json:"date"`
+	Value         float64    `json:"value"`
+	Unit          WeightUnit `json:"unit"`
+	Min           float64    `json:"min"`
+	Max           float64    `json:"max"`
+	Count         int        `json:"count"`
+	BodyCondition float64    `json:"bodyCondition,omitempty"`
+}
+
+// WeightSeries converts weights to one unit and, unless interval is raw,
+// averages them per day, week or month in loc.
+func WeightSeries(weights []Weight, unit WeightUnit, interval WeightInterval, loc *time.Location) []WeightPoint {
+	sorted := make([]Weight, len(weights))
+	copy(sorted, weights)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Date < sorted[j].Date })
+
+	points := []WeightPoint{}
+	var conditionSum float64
+	var conditionCount int
+
+	flush := func() {
+		if len(points) == 0 {
+			return
+		}
+		last := &points[len(points)-1]
+		last.Value = round(last.Value/float64(last.Count), 2)
+		if conditionCount > 0 {
+			last.BodyCondition = round(conditionSum/float64(conditionCount), 1)
+		}
+		conditionSum, conditionCount = 0, 0
+	}
+
+	for _, w := range sorted {
+		value := w.In(unit)
+		date := w.Date.Time().In(loc)
+		if interval != WeightRaw {
+			date = interval.start(date)
+		}
+
+		if interval == WeightRaw || len(points) == 0 || !points[len(points)-1].Date.Equal(date) {
+			flush()
+			points = append(points, WeightPoint{Date: date, Unit: unit, Min: value, Max: value})
+		}
+
+		last := &points[len(points)-1]
+		last.Value += value
+		last.Count++
+		last.Min = math.Min(last.Min, value)
+		last.Max = math.Max(last.Max, value)
+
+		if w.BodyCondition > 0 {
+			conditionSum += float64(w.BodyCondition)
+			conditionCount++
+		}
+	}
+	flush()
+
+	for i := range points {
+		points[i].Min = round(points[i].Min, 2)
+		points[i].Max = round(points[i].Max, 2)
+	}
+
+	return points
+}
+
+func round(v float64, places int) float64 {
+	scale := math.Pow(10, float64(places))
+	return math.Round(v*scale) / scale
+}
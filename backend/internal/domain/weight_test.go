@@ -0,0 +1,149 @@
+package domain
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func weighIn(at time.Time, value float64, unit WeightUnit, condition int) Weight {
+	return Weight{Value: value, Unit: unit, Date: primitive.NewDateTimeFromTime(at), BodyCondition: condition}
+}
+
+func TestWeightValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		weight Weight
+		want   error
+	}{
+		{"valid", Weight{Value: 4.2, Unit: Kilograms}, nil},
+		{"with condition", Weight{Value: 9, Unit: Pounds, BodyCondition: 5}, nil},
+		{"zero", Weight{Value: 0, Unit: Kilograms}, ErrInvalidWeight},
+		{"negative", Weight{Value: -1, Unit: Kilograms}, ErrInvalidWeight},
+		{"not a number", Weight{Value: math.NaN(), Unit: Kilograms}, ErrInvalidWeight},
+		{"infinite", Weight{Value: math.Inf(1), Unit: Kilograms}, ErrInvalidWeight},
+		{"unknown unit", Weight{Value: 1, Unit: "st"}, ErrInvalidWeightUnit},
+		{"condition too high", Weight{Value: 1, Unit: Kilograms, BodyCondition: 10}, ErrInvalidBodyCondition},
+	}
+
+	for _, tt := range tests {
+		if err := tt.weight.Validate(); err != tt.want {
+			t.Errorf("%s: Validate = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestConvertWeight(t *testing.T) {
+	tests := []struct {
+		value    float64
+		from, to WeightUnit
+		want     float64
+	}{
+		{1, Kilograms, Kilograms, 1},
+		{1, Kilograms, Pounds, 2.2046},
+		{10, Pounds, Kilograms, 4.5359},
+	}
+
+	for _, tt := range tests {
+		if got := ConvertWeight(tt.value, tt.from, tt.to); math.Abs(got-tt.want) > 0.0001 {
+			t.Errorf("ConvertWeight(%v, %s, %s) = %v, want %v", tt.value, tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestWeightSeries(t *testing.T) {
+	chicago, err := time.LoadLocation("America/Chicago")
+	if err != nil {
+		t.Skip("time zone data unavailable:", err)
+	}
+
+	at := func(month time.Month, day, hour int) time.Time {
+		return time.Date(2024, month, day, hour, 0, 0, 0, chicago)
+	}
+
+	weights := []Weight{
+		// Out of order on purpose.
+		weighIn(at(3, 5, 9), 4.4, Kilograms, 0),
+		weighIn(at(3, 4, 8), 4, Kilograms, 4),
+		weighIn(at(3, 4, 20), 10, Pounds, 6),
+		weighIn(at(3, 11, 9), 5, Kilograms, 5),
+		weighIn(at(4, 1, 9), 5.2, Kilograms, 0),
+	}
+
+	tests := []struct {
+		name     string
+		unit     WeightUnit
+		interval WeightInterval
+		want     []WeightPoint
+	}{
+		{
+			name:     "raw",
+			unit:     Kilograms,
+			interval: WeightRaw,
+			want: []WeightPoint{
+				{Date: at(3, 4, 8), Value: 4, Min: 4, Max: 4, Count: 1, BodyCondition: 4},
+				{Date: at(3, 4, 20), Value: 4.54, Min: 4.54, Max: 4.54, Count: 1, BodyCondition: 6},
+				{Date: at(3, 5, 9), Value: 4.4, Min: 4.4, Max: 4.4, Count: 1},
+				{Date: at(3, 11, 9), Value: 5, Min: 5, Max: 5, Count: 1, BodyCondition: 5},
+				{Date: at(4, 1, 9), Value: 5.2, Min: 5.2, Max: 5.2, Count: 1},
+			},
+		},
+		{
+			name:     "daily",
+			unit:     Kilograms,
+			interval: WeightDay,
+			want: []WeightPoint{
+				{Date: at(3, 4, 0), Value: 4.27, Min: 4, Max: 4.54, Count: 2, BodyCondition: 5},
+				{Date: at(3, 5, 0), Value: 4.4, Min: 4.4, Max: 4.4, Count: 1},
+				{Date: at(3, 11, 0), Value: 5, Min: 5, Max: 5, Count: 1, BodyCondition: 5},
+				{Date: at(4, 1, 0), Value: 5.2, Min: 5.2, Max: 5.2, Count: 1},
+			},
+		},
+		{
+			name:     "weekly starts on Monday",
+			unit:     Kilograms,
+			interval: WeightWeek,
+			want: []WeightPoint{
+				{Date: at(3, 4, 0), Value: 4.31, Min: 4, Max: 4.54, Count: 3, BodyCondition: 5},
+				{Date: at(3, 11, 0), Value: 5, Min: 5, Max: 5, Count: 1, BodyCondition: 5},
+				{Date: at(4, 1, 0), Value: 5.2, Min: 5.2, Max: 5.2, Count: 1},
+			},
+		},
+		{
+			name:     "monthly in pounds",
+			unit:     Pounds,
+			interval: WeightMonth,
+			want: []WeightPoint{
+				{Date: at(3, 1, 0), Value: 9.89, Min: 8.82, Max: 11.02, Count: 4, BodyCondition: 5},
+				{Date: at(4, 1, 0), Value: 11.46, Min: 11.46, Max: 11.46, Count: 1},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := WeightSeries(weights, tt.unit, tt.interval, chicago)
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d points, want %d: %+v", len(got), len(tt.want), got)
+			}
+
+			for i, want := range tt.want {
+				want.Unit = tt.unit
+				if !got[i].Date.Equal(want.Date) {
+					t.Errorf("point %d date = %s, want %s", i, got[i].Date, want.Date)
+				}
+				got[i].Date = want.Date
+				if got[i] != want {
+					t.Errorf("point %d = %+v, want %+v", i, got[i], want)
+				}
+			}
+		})
+	}
+
+	if got := WeightSeries(nil, Kilograms, WeightDay, chicago); got == nil || len(got) != 0 {
+		t.Errorf("empty series = %#v, want an empty slice", got)
+	}
+}
@@ -0,0 +1,166 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"testing"
+)
+
+// exifSegment builds an APP1 segment holding a single orientation entry.
+func exifSegment(order binary.ByteOrder, orientation uint16) []byte {
+	tiff := make([]byte, 8+2+12+4)
+	if order == binary.LittleEndian {
+		copy(tiff, "II")
+	} else {
+		copy(tiff, "MM")
+	}
+	order.PutUint16(tiff[2:], 42)
+	order.PutUint32(tiff[4:], 8)
+	order.PutUint16(tiff[8:], 1)
+	order.PutUint16(tiff[10:], exifOrientationTag)
+	order.PutUint16(tiff[12:], 3)
+	order.PutUint32(tiff[14:], 1)
+	order.PutUint16(tiff[18:], orientation)
+
+	payload := append([]byte("Exif\x00\x00"), tiff...)
+
+	segment := []byte{0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(segment[2:], uint16(len(payload)+2))
+	return append(segment, payload...)
+}
+
+// withSegments inserts segments straight after a JPEG's SOI marker.
+func withSegments(jpegData []byte, segments ...[]byte) []byte {
+	out := append([]byte{}, jpegData[:2]...)
+	for _, segment := range segments {
+		out = append(out, segment...)
+	}
+	return append(out, jpegData[2:]...)
+}
+
+func testJPEG(t *testing.T, w, h int) []byte {
+	t.Helper()
+
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(x * 40), G: uint8(y * 40), B: 128, A: 255})
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, nil); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestExifOrientation(t *testing.T) {
+	plain := testJPEG(t, 4, 2)
+	app0 := []byte{0xFF, 0xE0, 0x00, 0x04, 0x00, 0x00}
+
+	tests := []struct {
+		name string
+		data []byte
+		want int
+	}{
+		{"no exif", plain, 1},
+		{"little endian", withSegments(plain, exifSegment(binary.LittleEndian, 6)), 6},
+		{"big endian", withSegments(plain, exifSegment(binary.BigEndian, 8)), 8},
+		{"after another segment", withSegments(plain, app0, exifSegment(binary.BigEndian, 3)), 3},
+		{"out of range", withSegments(plain, exifSegment(binary.LittleEndian, 9)), 1},
+		{"zero", withSegments(plain, exifSegment(binary.LittleEndian, 0)), 1},
+		{"truncated segment", withSegments(plain, exifSegment(binary.LittleEndian, 6)[:12]), 1},
+		{"not a jpeg", []byte("\x89PNG\r\n\x1a\n"), 1},
+		{"empty", nil, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := exifOrientation(tt.data); got != tt.want {
+				t.Errorf("exifOrientation = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOrient(t *testing.T) {
+	// A 3x2 image whose pixels are numbered row by row:
+	//
+	//	1 2 3
+	//	4 5 6
+	src := image.NewNRGBA(image.Rect(0, 0, 3, 2))
+	for i := 0; i < 6; i++ {
+		src.SetNRGBA(i%3, i/3, color.NRGBA{R: uint8(i + 1), A: 255})
+	}
+
+	tests := []struct {
+		orientation int
+		want        [][]uint8
+	}{
+		{1, [][]uint8{{1, 2, 3}, {4, 5, 6}}},
+		{2, [][]uint8{{3, 2, 1}, {6, 5, 4}}},
+		{3, [][]uint8{{6, 5, 4}, {3, 2, 1}}},
+		{4, [][]uint8{{4, 5, 6}, {1, 2, 3}}},
+		{5, [][]uint8{{1, 4}, {2, 5}, {3, 6}}},
+		{6, [][]uint8{{4, 1}, {5, 2}, {6, 3}}},
+		{7, [][]uint8{{6, 3}, {5, 2}, {4, 1}}},
+		{8, [][]uint8{{3, 6}, {2, 5}, {1, 4}}},
+	}
+
+	for _, tt := range tests {
+		got := toNRGBA(orient(src, tt.orientation))
+
+		if got.Bounds().Dy() != len(tt.want) || got.Bounds().Dx() != len(tt.want[0]) {
+			t.Errorf("orientation %d: got %dx%d", tt.orientation, got.Bounds().Dx(), got.Bounds().Dy())
+			continue
+		}
+
+		for y, row := range tt.want {
+			for x, want := range row {
+				if r := got.NRGBAAt(x, y).R; r != want {
+					t.Errorf("orientation %d: pixel (%d,%d) = %d, want %d", tt.orientation, x, y, r, want)
+				}
+			}
+		}
+	}
+}
+
+func TestProcessRotatesAndStripsExif(t *testing.T) {
+	tests := []struct {
+		name        string
+		orientation uint16
+		wantW       int
+		wantH       int
+	}{
+		{"upright", 1, 40, 20},
+		{"rotated", 6, 20, 40},
+		{"mirrored", 2, 40, 20},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := withSegments(testJPEG(t, 40, 20), exifSegment(binary.BigEndian, tt.orientation))
+
+			results, err := Process(bytes.NewReader(data))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, encoded := range results {
+				if encoded.Width != tt.wantW || encoded.Height != tt.wantH {
+					t.Errorf("%s is %dx%d, want %dx%d", encoded.Variant.Name, encoded.Width, encoded.Height, tt.wantW, tt.wantH)
+				}
+				if bytes.Contains(encoded.Data, []byte("Exif\x00\x00")) {
+					t.Errorf("%s still carries EXIF data", encoded.Variant.Name)
+				}
+				if exifOrientation(encoded.Data) != 1 {
+					t.Errorf("%s kept an orientation tag", encoded.Variant.Name)
+				}
+			}
+		})
+	}
+}
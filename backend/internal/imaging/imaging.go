@@ -0,0 +1,221 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+const (
+	jpegQuality = 85
+
+	// MaxPixels guards against decompression bombs: small files that decode
+	// to enormous images.
+	MaxPixels = 50_000_000
+)
+
+var (
+	ErrUnsupportedFormat = errors.New("unsupported image format")
+	ErrTooLarge          = errors.New("image dimensions too large")
+)
+
+type Variant struct {
+	Name    string
+	MaxSize int
+}
+
+var (
+	Thumbnail = Variant{Name: "thumbnail", MaxSize: 200}
+	Card      = Variant{Name: "card", MaxSize: 600}
+	Full      = Variant{Name: "full", MaxSize: 1600}
+
+	// Variants is ordered from smallest to largest.
+	Variants = []Variant{Thumbnail, Card, Full}
+)
+
+type Encoded struct {
+	Variant     Variant
+	Data        []byte
+	ContentType string
+	Ext         string
+	Width       int
+	Height      int
+}
+
+// Process decodes an uploaded image, rotates it upright according to its
+// EXIF orientation and re-encodes it at every variant size. Re-encoding
+// drops all metadata, including GPS location.
+func Process(r io.Reader) ([]Encoded, error) {
+	raw, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	format, ok := DetectFormat(raw)
+	if !ok {
+		return nil, ErrUnsupportedFormat
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(raw))
+	if err != nil {
+		return nil, ErrUnsupportedFormat
+	}
+
+	if config.Width*config.Height > MaxPixels {
+		return nil, ErrTooLarge
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(raw))
+	if err != nil {
+		return nil, ErrUnsupportedFormat
+	}
+
+	if format == "jpeg" {
+		img = orient(img, exifOrientation(raw))
+	}
+
+	opaque := isOpaque(img)
+
+	// Scale from the largest variant down so each resize starts from the
+	// smallest source that still has enough detail.
+	results := make([]Encoded, len(Variants))
+	for i := len(Variants) - 1; i >= 0; i-- {
+		img = fit(img, Variants[i].MaxSize)
+
+		encoded, err := encode(img, opaque)
+		if err != nil {
+			return nil, err
+		}
+
+		encoded.Variant = Variants[i]
+		results[i] = encoded
+	}
+
+	return results, nil
+}
+
+func encode(img image.Image, opaque bool) (Encoded, error) {
+	var buf bytes.Buffer
+	bounds := img.Bounds()
+
+	if opaque {
+		if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegQuality}); err != nil {
+			return Encoded{}, err
+		}
+		return Encoded{Data: buf.Bytes(), ContentType: "image/jpeg", Ext: ".jpg", Width: bounds.Dx(), Height: bounds.Dy()}, nil
+	}
+
+	if err := png.Encode(&buf, img); err != nil {
+		return Encoded{}, err
+	}
+	return Encoded{Data: buf.Bytes(), ContentType: "image/png", Ext: ".png", Width: bounds.Dx(), Height: bounds.Dy()}, nil
+}
+
+// fit scales img down to fit a maxSize square, never scaling up.
+func fit(img image.Image, maxSize int) image.Image {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+
+	if w <= maxSize && h <= maxSize {
+		return toNRGBA(img)
+	}
+
+	if w >= h {
+		h = h * maxSize / w
+		w = maxSize
+	} else {
+		w = w * maxSize / h
+		h = maxSize
+	}
+
+	if w < 1 {
+		w = 1
+	}
+	if h < 1 {
+		h = 1
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, w, h))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, bounds, draw.Src, nil)
+
+	return dst
+}
+
+func toNRGBA(img image.Image) *image.NRGBA {
+	if nrgba, ok := img.(*image.NRGBA); ok && nrgba.Bounds().Min == (image.Point{}) {
+		return nrgba
+	}
+
+	bounds := img.Bounds()
+	dst := image.NewNRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(dst, dst.Bounds(), img, bounds.Min, draw.Src)
+
+	return dst
+}
+
+func isOpaque(img image.Image) bool {
+	if o, ok := img.(interface{ Opaque() bool }); ok {
+		return o.Opaque()
+	}
+
+	bounds := img.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			if _, _, _, a := img.At(x, y).RGBA(); a != 0xffff {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// orient applies one of the eight EXIF orientations so the image displays
+// upright without relying on the viewer honouring the tag.
+func orient(img image.Image, orientation int) image.Image {
+	if orientation < 2 || orientation > 8 {
+		return img
+	}
+
+	src := toNRGBA(img)
+	w, h := src.Bounds().Dx(), src.Bounds().Dy()
+
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, dw, dh))
+
+	for y := 0; y < dh; y++ {
+		for x := 0; x < dw; x++ {
+			var sx, sy int
+			switch orientation {
+			case 2:
+				sx, sy = w-1-x, y
+			case 3:
+				sx, sy = w-1-x, h-1-y
+			case 4:
+				sx, sy = x, h-1-y
+			case 5:
+				sx, sy = y, x
+			case 6:
+				sx, sy = y, h-1-x
+			case 7:
+				sx, sy = w-1-y, h-1-x
+			case 8:
+				sx, sy = w-1-y, x
+			}
+			dst.SetNRGBA(x, y, src.NRGBAAt(sx, sy))
+		}
+	}
+
+	return dst
+}
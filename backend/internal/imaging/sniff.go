@@ -0,0 +1,20 @@
+package imaging
+
+import "bytes"
+
+// DetectFormat identifies an upload from its magic bytes rather than the
+// client's file name or Content-Type. Only formats we can decode and
+// re-encode safely are recognised.
+func DetectFormat(header []byte) (string, bool) {
+	switch {
+	case bytes.HasPrefix(header, []byte{0xFF, 0xD8, 0xFF}):
+		return "jpeg", true
+	case bytes.HasPrefix(header, []byte("\x89PNG\r\n\x1a\n")):
+		return "png", true
+	case bytes.HasPrefix(header, []byte("GIF87a")), bytes.HasPrefix(header, []byte("GIF89a")):
+		return "gif", true
+	case len(header) >= 12 && bytes.Equal(header[:4], []byte("RIFF")) && bytes.Equal(header[8:12], []byte("WEBP")):
+		return "webp", true
+	}
+	return "", false
+}
@@ -1,7 +1,21 @@
 package middleware
 
-import "net/http"
+import (
+	"net/http"
 
-func CommonMiddleware(h http.Handler) http.Handler {
-	return Logger(Session(h))
+	"github.com/dspeirs7/animals/internal/domain"
+)
+
+func CommonMiddleware(sessions domain.SessionStore, h http.Handler) http.Handler {
+	return Logger(Session(sessions, WriteAccess(h)))
+}
+
+// CatalogMiddleware is for reference data anyone can read but only admins
+// change.
+func CatalogMiddleware(sessions domain.SessionStore, h http.Handler) http.Handler {
+	return Logger(Session(sessions, AdminWrites(h)))
+}
+
+func AdminMiddleware(sessions domain.SessionStore, h http.Handler) http.Handler {
+	return Logger(Session(sessions, AdminOnly(h)))
 }
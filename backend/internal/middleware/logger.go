@@ -8,7 +8,22 @@ import (
 
 func Logger(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		fmt.Printf("%s: \"%s %s\" from %s\n", time.Now().Format("2006-01-02 15:04:05"), r.Method, r.URL.String(), r.RemoteAddr)
+		fmt.Printf("%s: \"%s %s\" from %s\n", time.Now().Format("2006-01-02 15:04:05"), r.Method, redactedURL(r), r.RemoteAddr)
 		next.ServeHTTP(w, r)
 	})
 }
+
+// redactedURL is the request URL with the calendar feed's token hidden, so
+// the secret never reaches the logs.
+func redactedURL(r *http.Request) string {
+	query := r.URL.Query()
+	if !query.Has("token") {
+		return r.URL.String()
+	}
+
+	query.Set("token", "REDACTED")
+
+	u := *r.URL
+	u.RawQuery = query.Encode()
+	return u.String()
+}
@@ -1,34 +1,142 @@
 package middleware
 
 import (
+	"context"
+	"errors"
 	"net/http"
+	"time"
 
 	"github.com/dspeirs7/animals/internal/domain"
 )
 
-func Session(next http.Handler) http.Handler {
+type contextKey string
+
+const sessionKey contextKey = "session"
+
+func SessionFromContext(ctx context.Context) (*domain.Session, bool) {
+	session, ok := ctx.Value(sessionKey).(*domain.Session)
+	return session, ok
+}
+
+func isWrite(r *http.Request) bool {
+	return r.Method == http.MethodPost || r.Method == http.MethodPut || r.Method == http.MethodDelete
+}
+
+func unauthorized(w http.ResponseWriter) {
+	w.WriteHeader(http.StatusUnauthorized)
+	w.Write([]byte("Unauthorized"))
+}
+
+func forbidden(w http.ResponseWriter) {
+	w.WriteHeader(http.StatusForbidden)
+	w.Write([]byte("Forbidden"))
+}
+
+// Session attaches the caller's session to the request context when a valid
+// session cookie is present, and rejects writes made without one.
+func Session(sessions domain.SessionStore, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		session, err := lookupSession(w, r, sessions)
+		if err != nil {
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+
+		if session == nil && isWrite(r) {
+			unauthorized(w)
+			return
+		}
+
+		if session != nil {
+			r = r.WithContext(context.WithValue(r.Context(), sessionKey, session))
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// lookupSession returns a nil session when there is no usable cookie and an
+// error only when the store itself fails.
+func lookupSession(w http.ResponseWriter, r *http.Request, sessions domain.SessionStore) (*domain.Session, error) {
+	cookie, err := r.Cookie("session_token")
+	if err != nil {
+		return nil, nil
+	}
+
+	sessionId := cookie.Value
+	session, err := sessions.Get(r.Context(), sessionId)
+	if errors.Is(err, domain.ErrSessionNotFound) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	if session.IsExpired() {
+		_ = sessions.Delete(r.Context(), sessionId)
+		return nil, nil
+	}
+
+	expiresAt := time.Now().Add(domain.SessionDuration)
+	if err := sessions.Touch(r.Context(), sessionId, expiresAt); err == nil {
+		session.Expiry = expiresAt
+		http.SetCookie(w, &http.Cookie{Name: "session_token", Value: sessionId, Expires: expiresAt, Path: "/", SameSite: http.SameSiteLaxMode})
+	}
+
+	return session, nil
+}
+
+// WriteAccess keeps viewers to read-only requests.
+func WriteAccess(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if r.Method == http.MethodPost || r.Method == http.MethodPut || r.Method == http.MethodDelete {
-			cookie, err := r.Cookie("session_token")
-			if err != nil {
-				w.WriteHeader(http.StatusUnauthorized)
-				w.Write([]byte("Unauthorized"))
+		if isWrite(r) {
+			session, ok := SessionFromContext(r.Context())
+			if !ok {
+				unauthorized(w)
 				return
 			}
 
-			sessions := domain.GetSessions()
-			sessionId := cookie.Value
-			session, ok := sessions[sessionId]
+			if !session.Role.CanWrite() {
+				forbidden(w)
+				return
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// AdminWrites lets everyone read but keeps writes to admins.
+func AdminWrites(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if isWrite(r) {
+			session, ok := SessionFromContext(r.Context())
+			if !ok {
+				unauthorized(w)
+				return
+			}
+
+			if !session.Role.CanManageUsers() {
+				forbidden(w)
+				return
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// AdminOnly requires an admin session for every method but OPTIONS.
+func AdminOnly(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodOptions {
+			session, ok := SessionFromContext(r.Context())
 			if !ok {
-				w.WriteHeader(http.StatusUnauthorized)
-				w.Write([]byte("Unauthorized"))
+				unauthorized(w)
 				return
 			}
 
-			if session.IsExpired() {
-				delete(sessions, sessionId)
-				w.WriteHeader(http.StatusUnauthorized)
-				w.Write([]byte("Unauthorized"))
+			if !session.Role.CanManageUsers() {
+				forbidden(w)
 				return
 			}
 		}
@@ -2,11 +2,14 @@ package repository
 
 import (
 	"context"
+	"regexp"
+	"strings"
 
 	"github.com/dspeirs7/animals/internal/domain"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type mongoAnimalRepository struct {
@@ -20,52 +23,64 @@ func NewAnimalRepository(animalColl *mongo.Collection) domain.AnimalRepository {
 	}
 }
 
-func (m *mongoAnimalRepository) GetAllCats(ctx context.Context) ([]*domain.Animal, error) {
-	filter := bson.D{{Key: "type", Value: 1}}
-	cursor, err := m.animalColl.Find(ctx, filter)
+func (m *mongoAnimalRepository) Find(ctx context.Context, query domain.AnimalQuery) (*domain.AnimalPage, error) {
+	filter := animalFilter(query)
+
+	total, err := m.animalColl.CountDocuments(ctx, filter)
 	if err != nil {
 		return nil, err
 	}
 
-	var results []*domain.Animal
-
-	if err = cursor.All(ctx, &results); err != nil {
+	field, direction, err := animalSort(query.Sort)
+	if err != nil {
 		return nil, err
 	}
 
-	return results, nil
-}
+	if query.Cursor != "" {
+		after, err := cursorFilter(query.Cursor, field, direction)
+		if err != nil {
+			return nil, err
+		}
 
-func (m *mongoAnimalRepository) GetAllChickens(ctx context.Context) ([]*domain.Animal, error) {
-	filter := bson.D{{Key: "type", Value: 2}}
-	cursor, err := m.animalColl.Find(ctx, filter)
-	if err != nil {
-		return nil, err
+		filter = bson.D{{Key: "$and", Value: bson.A{filter, after}}}
 	}
 
-	var results []*domain.Animal
-
-	if err = cursor.All(ctx, &results); err != nil {
-		return nil, err
+	limit := query.Limit
+	if limit <= 0 {
+		limit = domain.DefaultAnimalLimit
+	} else if limit > domain.MaxAnimalLimit {
+		limit = domain.MaxAnimalLimit
 	}
 
-	return results, nil
-}
+	opts := options.Find().
+		SetSort(bson.D{{Key: field, Value: direction}, {Key: "_id", Value: direction}}).
+		SetLimit(limit + 1)
+
+	if query.Offset > 0 {
+		opts.SetSkip(query.Offset)
+	}
 
-func (m *mongoAnimalRepository) GetAllDogs(ctx context.Context) ([]*domain.Animal, error) {
-	filter := bson.D{{Key: "type", Value: 3}}
-	cursor, err := m.animalColl.Find(ctx, filter)
+	cursor, err := m.animalColl.Find(ctx, filter, opts)
 	if err != nil {
 		return nil, err
 	}
 
-	var results []*domain.Animal
+	results := []*domain.Animal{}
 
 	if err = cursor.All(ctx, &results); err != nil {
 		return nil, err
 	}
 
-	return results, nil
+	page := &domain.AnimalPage{Total: total}
+
+	if int64(len(results)) > limit {
+		results = results[:limit]
+		page.NextCursor = encodeCursor(results[len(results)-1], field)
+	}
+
+	page.Animals = results
+
+	return page, nil
 }
 
 func (m *mongoAnimalRepository) GetById(ctx context.Context, id string) (*domain.Animal, error) {
@@ -76,15 +91,22 @@ func (m *mongoAnimalRepository) GetById(ctx context.Context, id string) (*domain
 
 	var result domain.Animal
 
-	cursor := m.animalColl.FindOne(ctx, bson.M{"_id": objectId})
+	cursor := m.animalColl.FindOne(ctx, bson.M{"_id": objectId, "deletedAt": bson.M{"$exists": false}})
 	cursor.Decode(&result)
 
 	return &result, nil
 }
 
 func (m *mongoAnimalRepository) Insert(ctx context.Context, animal domain.Animal) (*domain.Animal, error) {
+	if err := m.identifiersInUse(ctx, animal); err != nil {
+		return nil, err
+	}
+
 	result, err := m.animalColl.InsertOne(ctx, animal)
 	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, domain.ErrIdentifierInUse
+		}
 		return nil, err
 	}
 
@@ -99,9 +121,17 @@ func (m *mongoAnimalRepository) Update(ctx context.Context, id string, animal do
 		return err
 	}
 
-	filter := bson.D{{Key: "_id", Value: objectId}}
+	animal.Id = objectId
+	if err := m.identifiersInUse(ctx, animal); err != nil {
+		return err
+	}
+
+	filter := bson.D{{Key: "_id", Value: objectId}, {Key: "deletedAt", Value: bson.M{"$exists": false}}}
 
 	if _, err := m.animalColl.ReplaceOne(ctx, filter, animal); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return domain.ErrIdentifierInUse
+		}
 		return err
 	}
 
@@ -129,22 +159,23 @@ func (m *mongoAnimalRepository) AddVaccinations(ctx context.Context, id string,
 		return err
 	}
 
-	change := bson.M{"$push": bson.M{"vaccinations": bson.M{"$each": vaccinations}}}
-
-	if _, err := m.animalColl.UpdateByID(ctx, objectId, change); err != nil {
-		return err
+	// A dose that has been given fulfils any pending dose of the same name.
+	var given bson.A
+	for _, v := range vaccinations {
+		if v.DateGiven != 0 {
+			pattern := "^" + regexp.QuoteMeta(strings.TrimSpace(v.Name)) + "$"
+			given = append(given, primitive.Regex{Pattern: pattern, Options: "i"})
+		}
 	}
 
-	return nil
-}
-
-func (m *mongoAnimalRepository) DeleteVaccination(ctx context.Context, id string, vaccination domain.Vaccination) error {
-	objectId, err := primitive.ObjectIDFromHex(id)
-	if err != nil {
-		return err
+	if len(given) > 0 {
+		pending := bson.M{"$pull": bson.M{"vaccinations": bson.M{"name": bson.M{"$in": given}, "dateGiven": bson.M{"$exists": false}}}}
+		if _, err := m.animalColl.UpdateByID(ctx, objectId, pending); err != nil {
+			return err
+		}
 	}
 
-	change := bson.M{"$pull": bson.M{"vaccinations": bson.M{"name": vaccination.Name, "dateGiven": vaccination.DateGiven, "dateNeeded": vaccination.DateNeeded}}}
+	change := bson.M{"$push": bson.M{"vaccinations": bson.M{"$each": vaccinations}}}
 
 	if _, err := m.animalColl.UpdateByID(ctx, objectId, change); err != nil {
 		return err
@@ -153,13 +184,13 @@ func (m *mongoAnimalRepository) DeleteVaccination(ctx context.Context, id string
 	return nil
 }
 
-func (m *mongoAnimalRepository) UpdateImageUrl(ctx context.Context, id string, imageUrl string) error {
+func (m *mongoAnimalRepository) DeleteVaccination(ctx context.Context, id string, vaccination domain.Vaccination) error {
 	objectId, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
 		return err
 	}
 
-	change := bson.M{"$set": bson.M{"imageUrl": imageUrl}}
+	change := bson.M{"$pull": bson.M{"vaccinations": bson.M{"name": vaccination.Name, "dateGiven": vaccination.DateGiven, "dateNeeded": vaccination.DateNeeded}}}
 
 	if _, err := m.animalColl.UpdateByID(ctx, objectId, change); err != nil {
 		return err
@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/dspeirs7/animals/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type breedRepository struct {
+	breedColl *mongo.Collection
+}
+
+// NewBreedRepository seeds the catalog with domain.DefaultBreeds the first
+// time it runs.
+func NewBreedRepository(ctx context.Context, breedColl *mongo.Collection) (domain.BreedRepository, error) {
+	count, err := breedColl.CountDocuments(ctx, bson.D{})
+	if err != nil {
+		return nil, err
+	}
+
+	if count == 0 {
+		docs := make([]interface{}, 0, len(domain.DefaultBreeds))
+		for _, breed := range domain.DefaultBreeds {
+			docs = append(docs, breed)
+		}
+
+		if _, err := breedColl.InsertMany(ctx, docs); err != nil && !mongo.IsDuplicateKeyError(err) {
+			return nil, err
+		}
+	}
+
+	return &breedRepository{breedColl: breedColl}, nil
+}
+
+func (m *breedRepository) List(ctx context.Context, animalType domain.AnimalType) ([]*domain.Breed, error) {
+	filter := bson.M{}
+	if animalType != 0 {
+		filter["type"] = animalType
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "type", Value: 1}, {Key: "name", Value: 1}})
+
+	cursor, err := m.breedColl.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	results := []*domain.Breed{}
+
+	if err = cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+func (m *breedRepository) Get(ctx context.Context, id domain.AnimalBreed) (*domain.Breed, error) {
+	var result domain.Breed
+
+	if err := m.breedColl.FindOne(ctx, bson.M{"_id": id}).Decode(&result); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, domain.ErrBreedNotFound
+		}
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// Insert adds a breed, numbering it after the highest existing id when it
+// doesn't bring its own.
+func (m *breedRepository) Insert(ctx context.Context, breed domain.Breed) (*domain.Breed, error) {
+	if breed.Id == 0 {
+		var last domain.Breed
+
+		opts := options.FindOne().SetSort(bson.D{{Key: "_id", Value: -1}})
+		if err := m.breedColl.FindOne(ctx, bson.D{}, opts).Decode(&last); err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, err
+		}
+
+		breed.Id = last.Id + 1
+	}
+
+	if _, err := m.breedColl.InsertOne(ctx, breed); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, domain.ErrBreedExists
+		}
+		return nil, err
+	}
+
+	return &breed, nil
+}
+
+func (m *breedRepository) Update(ctx context.Context, id domain.AnimalBreed, breed domain.Breed) error {
+	breed.Id = id
+
+	result, err := m.breedColl.ReplaceOne(ctx, bson.M{"_id": id}, breed)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return domain.ErrBreedNotFound
+	}
+
+	return nil
+}
+
+func (m *breedRepository) Delete(ctx context.Context, id domain.AnimalBreed) error {
+	result, err := m.breedColl.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		return domain.ErrBreedNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,167 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"regexp"
+
+	"github.com/dspeirs7/animals/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type contactRepository struct {
+	contactColl *mongo.Collection
+}
+
+func NewContactRepository(contactColl *mongo.Collection) domain.ContactRepository {
+	return &contactRepository{contactColl: contactColl}
+}
+
+func (m *contactRepository) List(ctx context.Context, namePrefix string) ([]*domain.Contact, error) {
+	filter := bson.M{}
+	if namePrefix != "" {
+		filter["name"] = primitive.Regex{Pattern: "^" + regexp.QuoteMeta(namePrefix), Options: "i"}
+	}
+
+	cursor, err := m.contactColl.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "name", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+
+	results := []*domain.Contact{}
+
+	if err = cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+func (m *contactRepository) GetById(ctx context.Context, id string) (*domain.Contact, error) {
+	objectId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, domain.ErrContactNotFound
+	}
+
+	var result domain.Contact
+
+	if err := m.contactColl.FindOne(ctx, bson.M{"_id": objectId}).Decode(&result); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, domain.ErrContactNotFound
+		}
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+func (m *contactRepository) Insert(ctx context.Context, contact domain.Contact) (*domain.Contact, error) {
+	contact.Id = primitive.NilObjectID
+
+	result, err := m.contactColl.InsertOne(ctx, contact)
+	if err != nil {
+		return nil, err
+	}
+
+	contact.Id = result.InsertedID.(primitive.ObjectID)
+
+	return &contact, nil
+}
+
+func (m *contactRepository) Update(ctx context.Context, id string, contact domain.Contact) error {
+	objectId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return domain.ErrContactNotFound
+	}
+
+	contact.Id = objectId
+
+	result, err := m.contactColl.ReplaceOne(ctx, bson.M{"_id": objectId}, contact)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return domain.ErrContactNotFound
+	}
+
+	return nil
+}
+
+func (m *contactRepository) Delete(ctx context.Context, id string) error {
+	objectId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return domain.ErrContactNotFound
+	}
+
+	result, err := m.contactColl.DeleteOne(ctx, bson.M{"_id": objectId})
+	if err != nil {
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		return domain.ErrContactNotFound
+	}
+
+	return nil
+}
+
+// statusFilter matches animals in status, counting animals saved before
+// statuses existed as available.
+func statusFilter(statuses []domain.Status) bson.M {
+	in := bson.A{}
+	for _, status := range statuses {
+		in = append(in, status)
+		if status == domain.StatusAvailable {
+			in = append(in, nil)
+		}
+	}
+	return bson.M{"$in": in}
+}
+
+// SetStatus records a status change, provided nobody changed the status
+// since change.From was read.
+func (m *mongoAnimalRepository) SetStatus(ctx context.Context, id primitive.ObjectID, change domain.StatusChange) error {
+	filter := bson.M{"_id": id, "status": statusFilter([]domain.Status{change.From})}
+
+	set := bson.M{"status": change.Status}
+	update := bson.M{"$set": set, "$push": bson.M{"statusHistory": change}}
+
+	if change.ContactId != nil {
+		set["contactId"] = change.ContactId
+	} else {
+		update["$unset"] = bson.M{"contactId": ""}
+	}
+
+	result, err := m.animalColl.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return domain.ErrStatusChanged
+	}
+
+	return nil
+}
+
+// ContactAnimals lists the animals a contact has had, now or in the past.
+func (m *mongoAnimalRepository) ContactAnimals(ctx context.Context, contactId primitive.ObjectID) ([]*domain.Animal, error) {
+	filter := bson.M{"statusHistory.contactId": contactId, "deletedAt": bson.M{"$exists": false}}
+
+	cursor, err := m.animalColl.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "name", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+
+	results := []*domain.Animal{}
+
+	if err = cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
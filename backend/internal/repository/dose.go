@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/dspeirs7/animals/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type doseRepository struct {
+	doseColl *mongo.Collection
+}
+
+func NewDoseRepository(ctx context.Context, doseColl *mongo.Collection) (domain.DoseRepository, error) {
+	indexes := []mongo.IndexModel{
+		{Keys: bson.D{{Key: "due", Value: 1}}},
+		{Keys: bson.D{{Key: "medicationId", Value: 1}}},
+		{Keys: bson.D{{Key: "animalId", Value: 1}}},
+	}
+
+	if _, err := doseColl.Indexes().CreateMany(ctx, indexes); err != nil {
+		return nil, err
+	}
+
+	return &doseRepository{doseColl: doseColl}, nil
+}
+
+func (m *doseRepository) Between(ctx context.Context, from, to time.Time) ([]*domain.Dose, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "due", Value: 1}})
+
+	cursor, err := m.doseColl.Find(ctx, bson.M{"due": dateRange(from, to)}, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	results := []*domain.Dose{}
+
+	if err = cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// Administer stores a given dose. Its Id is the occurrence, so giving the
+// same dose twice fails with ErrDoseAdministered.
+func (m *doseRepository) Administer(ctx context.Context, dose domain.Dose) error {
+	if _, err := m.doseColl.InsertOne(ctx, dose); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return domain.ErrDoseAdministered
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (m *doseRepository) DeleteForMedication(ctx context.Context, medicationId primitive.ObjectID) error {
+	_, err := m.doseColl.DeleteMany(ctx, bson.M{"medicationId": medicationId})
+	return err
+}
+
+func (m *doseRepository) DeleteForAnimal(ctx context.Context, animalId primitive.ObjectID) error {
+	_, err := m.doseColl.DeleteMany(ctx, bson.M{"animalId": animalId})
+	return err
+}
@@ -0,0 +1,282 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/dspeirs7/animals/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const dayMillis = 24 * 60 * 60 * 1000
+
+type eggRepository struct {
+	eggColl *mongo.Collection
+}
+
+func NewEggRepository(ctx context.Context, eggColl *mongo.Collection) (domain.EggRepository, error) {
+	// One log per hen or coop per day; recording again replaces it.
+	index := mongo.IndexModel{
+		Keys:    bson.D{{Key: "date", Value: 1}, {Key: "animalId", Value: 1}, {Key: "coop", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	}
+
+	if _, err := eggColl.Indexes().CreateOne(ctx, index); err != nil {
+		return nil, err
+	}
+
+	return &eggRepository{eggColl: eggColl}, nil
+}
+
+func dateRange(from, to time.Time) bson.M {
+	filter := bson.M{}
+	if !from.IsZero() {
+		filter["$gte"] = from
+	}
+	if !to.IsZero() {
+		filter["$lt"] = to
+	}
+	return filter
+}
+
+func (m *eggRepository) Find(ctx context.Context, query domain.EggLogQuery) ([]*domain.EggLog, error) {
+	filter := bson.M{}
+	if r := dateRange(query.From, query.To); len(r) > 0 {
+		filter["date"] = r
+	}
+	if query.AnimalId != nil {
+		filter["animalId"] = query.AnimalId
+	}
+	if query.Coop != "" {
+		filter["coop"] = query.Coop
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "date", Value: -1}, {Key: "coop", Value: 1}, {Key: "animalId", Value: 1}})
+
+	cursor, err := m.eggColl.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	results := []*domain.EggLog{}
+
+	if err = cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+func (m *eggRepository) Record(ctx context.Context, log domain.EggLog) (*domain.EggLog, error) {
+	filter := bson.M{"date": log.Date}
+	if log.AnimalId != nil {
+		filter["animalId"] = log.AnimalId
+	} else {
+		filter["coop"] = log.Coop
+		filter["animalId"] = bson.M{"$exists": false}
+	}
+
+	set := bson.M{"count": log.Count, "recordedBy": log.RecordedBy}
+	update := bson.M{"$set": set}
+	if log.Note != "" {
+		set["note"] = log.Note
+	} else {
+		update["$unset"] = bson.M{"note": ""}
+	}
+
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	var result domain.EggLog
+
+	if err := m.eggColl.FindOneAndUpdate(ctx, filter, update, opts).Decode(&result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+func (m *eggRepository) Delete(ctx context.Context, id string) error {
+	objectId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+
+	result, err := m.eggColl.DeleteOne(ctx, bson.M{"_id": objectId})
+	if err != nil {
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		return domain.ErrEggLogNotFound
+	}
+
+	return nil
+}
+
+func (m *eggRepository) DeleteForAnimal(ctx context.Context, animalId primitive.ObjectID) error {
+	_, err := m.eggColl.DeleteMany(ctx, bson.M{"animalId": animalId})
+	return err
+}
+
+func (m *eggRepository) Stats(ctx context.Context, query domain.EggStatsQuery) ([]*domain.EggStat, error) {
+	match := bson.M{}
+	if r := dateRange(query.From, query.To); len(r) > 0 {
+		match["date"] = r
+	}
+
+	var group, label interface{}
+
+	switch query.Group {
+	case domain.EggByBird:
+		match["animalId"] = bson.M{"$exists": true}
+		group = bson.M{"$toString": "$animalId"}
+		label = "$animal.name"
+	case domain.EggBreed:
+		// Breeds are numbered; coop logs have none and land in "0".
+		group = bson.M{"$toString": bson.M{"$ifNull": bson.A{"$animal.breed", 0}}}
+		label = nil
+	case domain.EggCoop:
+		match["coop"] = bson.M{"$exists": true}
+		group = "$coop"
+		label = "$coop"
+	default:
+		group = ""
+		label = ""
+	}
+
+	window := query.Window
+	if window <= 0 {
+		window = domain.DefaultEggWindow
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$lookup", Value: bson.M{
+			"from":         "animals",
+			"localField":   "animalId",
+			"foreignField": "_id",
+			"as":           "animal",
+		}}},
+		{{Key: "$unwind", Value: bson.M{"path": "$animal", "preserveNullAndEmptyArrays": true}}},
+		// Coop logs have no animal and pass; hens in the trash don't.
+		{{Key: "$match", Value: bson.M{"animal.deletedAt": bson.M{"$exists": false}}}},
+		{{Key: "$group", Value: bson.M{
+			"_id": bson.M{
+				"period": bson.M{"$dateTrunc": bson.M{
+					"date":        "$date",
+					"unit":        string(query.Interval),
+					"startOfWeek": "monday",
+					"timezone":    "UTC",
+				}},
+				"group": group,
+			},
+			"label": bson.M{"$first": label},
+			"eggs":  bson.M{"$sum": "$count"},
+			"logs":  bson.M{"$sum": 1},
+		}}},
+		{{Key: "$setWindowFields", Value: bson.M{
+			"partitionBy": "$_id.group",
+			"sortBy":      bson.M{"_id.period": 1},
+			"output": bson.M{
+				"rollingAverage": bson.M{
+					"$avg":   "$eggs",
+					"window": bson.M{"documents": bson.A{-(window - 1), 0}},
+				},
+			},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "_id.period", Value: 1}, {Key: "label", Value: 1}}}},
+		{{Key: "$project", Value: bson.M{
+			"_id":            0,
+			"period":         "$_id.period",
+			"group":          "$_id.group",
+			"label":          1,
+			"eggs":           1,
+			"logs":           1,
+			"rollingAverage": 1,
+		}}},
+	}
+
+	cursor, err := m.eggColl.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+
+	results := []*domain.EggStat{}
+
+	if err = cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// Streaks finds runs of laying days per hen: numbering each hen's laying
+// days in order and subtracting that from the day number gives the same
+// value for every day of an unbroken run.
+func (m *eggRepository) Streaks(ctx context.Context, since time.Time) ([]*domain.EggStreak, error) {
+	match := bson.M{"animalId": bson.M{"$exists": true}, "count": bson.M{"$gt": 0}}
+	if !since.IsZero() {
+		match["date"] = bson.M{"$gte": since}
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$setWindowFields", Value: bson.M{
+			"partitionBy": "$animalId",
+			"sortBy":      bson.M{"date": 1},
+			"output":      bson.M{"n": bson.M{"$documentNumber": bson.M{}}},
+		}}},
+		{{Key: "$group", Value: bson.M{
+			"_id": bson.M{
+				"animal": "$animalId",
+				"run": bson.M{"$subtract": bson.A{
+					bson.M{"$divide": bson.A{bson.M{"$toLong": "$date"}, dayMillis}},
+					"$n",
+				}},
+			},
+			"end":    bson.M{"$max": "$date"},
+			"length": bson.M{"$sum": 1},
+		}}},
+		{{Key: "$sort", Value: bson.M{"end": -1}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":        "$_id.animal",
+			"longest":    bson.M{"$max": "$length"},
+			"lastLaid":   bson.M{"$first": "$end"},
+			"lastLength": bson.M{"$first": "$length"},
+		}}},
+		{{Key: "$lookup", Value: bson.M{
+			"from":         "animals",
+			"localField":   "_id",
+			"foreignField": "_id",
+			"as":           "animal",
+		}}},
+		{{Key: "$unwind", Value: "$animal"}},
+		{{Key: "$match", Value: bson.M{"animal.deletedAt": bson.M{"$exists": false}}}},
+		{{Key: "$sort", Value: bson.D{{Key: "longest", Value: -1}, {Key: "animal.name", Value: 1}}}},
+		{{Key: "$project", Value: bson.M{
+			"_id":        0,
+			"animalId":   "$_id",
+			"name":       "$animal.name",
+			"breed":      "$animal.breed",
+			"longest":    1,
+			"lastLaid":   1,
+			"lastLength": 1,
+		}}},
+	}
+
+	cursor, err := m.eggColl.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+
+	results := []*domain.EggStreak{}
+
+	if err = cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
@@ -0,0 +1,179 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/dspeirs7/animals/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type feedingRepository struct {
+	planColl    *mongo.Collection
+	feedingColl *mongo.Collection
+}
+
+func NewFeedingRepository(ctx context.Context, planColl *mongo.Collection, feedingColl *mongo.Collection) (domain.FeedingRepository, error) {
+	if _, err := planColl.Indexes().CreateOne(ctx, mongo.IndexModel{Keys: bson.D{{Key: "animalIds", Value: 1}}}); err != nil {
+		return nil, err
+	}
+
+	if _, err := feedingColl.Indexes().CreateOne(ctx, mongo.IndexModel{Keys: bson.D{{Key: "due", Value: 1}}}); err != nil {
+		return nil, err
+	}
+
+	return &feedingRepository{planColl: planColl, feedingColl: feedingColl}, nil
+}
+
+func (m *feedingRepository) findPlans(ctx context.Context, filter bson.M) ([]*domain.FeedingPlan, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "name", Value: 1}, {Key: "_id", Value: 1}})
+
+	cursor, err := m.planColl.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	results := []*domain.FeedingPlan{}
+
+	if err = cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+func (m *feedingRepository) Plans(ctx context.Context, activeOnly bool) ([]*domain.FeedingPlan, error) {
+	filter := bson.M{}
+	if activeOnly {
+		filter["paused"] = bson.M{"$ne": true}
+	}
+	return m.findPlans(ctx, filter)
+}
+
+func (m *feedingRepository) PlansFor(ctx context.Context, animal *domain.Animal) ([]*domain.FeedingPlan, error) {
+	return m.findPlans(ctx, bson.M{"$or": bson.A{
+		bson.M{"animalIds": animal.Id},
+		bson.M{"type": animal.Type},
+	}})
+}
+
+func (m *feedingRepository) GetPlan(ctx context.Context, id string) (*domain.FeedingPlan, error) {
+	objectId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var result domain.FeedingPlan
+
+	if err := m.planColl.FindOne(ctx, bson.M{"_id": objectId}).Decode(&result); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, domain.ErrFeedingPlanNotFound
+		}
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+func (m *feedingRepository) InsertPlan(ctx context.Context, plan domain.FeedingPlan) (*domain.FeedingPlan, error) {
+	plan.Id = primitive.NilObjectID
+
+	result, err := m.planColl.InsertOne(ctx, plan)
+	if err != nil {
+		return nil, err
+	}
+
+	plan.Id = result.InsertedID.(primitive.ObjectID)
+
+	return &plan, nil
+}
+
+func (m *feedingRepository) UpdatePlan(ctx context.Context, id string, plan domain.FeedingPlan) error {
+	objectId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+
+	plan.Id = objectId
+
+	result, err := m.planColl.ReplaceOne(ctx, bson.M{"_id": objectId}, plan)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return domain.ErrFeedingPlanNotFound
+	}
+
+	return nil
+}
+
+// DeletePlan removes the plan; the feedings already given under it stay in
+// the log.
+func (m *feedingRepository) DeletePlan(ctx context.Context, id string) error {
+	objectId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+
+	result, err := m.planColl.DeleteOne(ctx, bson.M{"_id": objectId})
+	if err != nil {
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		return domain.ErrFeedingPlanNotFound
+	}
+
+	return nil
+}
+
+// RemoveAnimal takes a deleted animal out of every plan, and drops the
+// plans that were only for it.
+func (m *feedingRepository) RemoveAnimal(ctx context.Context, animalId primitive.ObjectID) error {
+	if _, err := m.planColl.UpdateMany(ctx, bson.M{"animalIds": animalId}, bson.M{"$pull": bson.M{"animalIds": animalId}}); err != nil {
+		return err
+	}
+
+	_, err := m.planColl.DeleteMany(ctx, bson.M{"animalIds": bson.A{}, "type": bson.M{"$exists": false}})
+	return err
+}
+
+func (m *feedingRepository) Feedings(ctx context.Context, from, to time.Time) ([]*domain.Feeding, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "due", Value: -1}})
+
+	filter := bson.M{}
+	if r := dateRange(from, to); len(r) > 0 {
+		filter["due"] = r
+	}
+
+	cursor, err := m.feedingColl.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	results := []*domain.Feeding{}
+
+	if err = cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// Feed records a given feeding. Its Id is the scheduled feeding, so a second
+// caretaker recording the same one gets ErrAlreadyFed.
+func (m *feedingRepository) Feed(ctx context.Context, feeding domain.Feeding) error {
+	if _, err := m.feedingColl.InsertOne(ctx, feeding); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return domain.ErrAlreadyFed
+		}
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/dspeirs7/animals/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type fieldRepository struct {
+	fieldColl *mongo.Collection
+}
+
+func NewFieldRepository(ctx context.Context, fieldColl *mongo.Collection) (domain.FieldRepository, error) {
+	index := mongo.IndexModel{
+		Keys:    bson.D{{Key: "type", Value: 1}, {Key: "key", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	}
+
+	if _, err := fieldColl.Indexes().CreateOne(ctx, index); err != nil {
+		return nil, err
+	}
+
+	return &fieldRepository{fieldColl: fieldColl}, nil
+}
+
+func (m *fieldRepository) List(ctx context.Context, animalType domain.AnimalType) ([]*domain.FieldDefinition, error) {
+	filter := bson.M{}
+	if animalType != 0 {
+		filter["type"] = animalType
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "type", Value: 1}, {Key: "key", Value: 1}})
+
+	cursor, err := m.fieldColl.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	results := []*domain.FieldDefinition{}
+
+	if err = cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+func (m *fieldRepository) GetById(ctx context.Context, id string) (*domain.FieldDefinition, error) {
+	objectId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, domain.ErrFieldNotFound
+	}
+
+	var result domain.FieldDefinition
+
+	if err := m.fieldColl.FindOne(ctx, bson.M{"_id": objectId}).Decode(&result); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, domain.ErrFieldNotFound
+		}
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+func (m *fieldRepository) Insert(ctx context.Context, field domain.FieldDefinition) (*domain.FieldDefinition, error) {
+	field.Id = primitive.NilObjectID
+
+	result, err := m.fieldColl.InsertOne(ctx, field)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, domain.ErrFieldExists
+		}
+		return nil, err
+	}
+
+	field.Id = result.InsertedID.(primitive.ObjectID)
+
+	return &field, nil
+}
+
+func (m *fieldRepository) Update(ctx context.Context, id string, field domain.FieldDefinition) error {
+	objectId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return domain.ErrFieldNotFound
+	}
+
+	field.Id = objectId
+
+	result, err := m.fieldColl.ReplaceOne(ctx, bson.M{"_id": objectId}, field)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return domain.ErrFieldExists
+		}
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return domain.ErrFieldNotFound
+	}
+
+	return nil
+}
+
+func (m *fieldRepository) Delete(ctx context.Context, id string) error {
+	objectId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return domain.ErrFieldNotFound
+	}
+
+	result, err := m.fieldColl.DeleteOne(ctx, bson.M{"_id": objectId})
+	if err != nil {
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		return domain.ErrFieldNotFound
+	}
+
+	return nil
+}
+
+// RemoveAttribute drops a deleted field's values from every animal of its
+// type.
+func (m *mongoAnimalRepository) RemoveAttribute(ctx context.Context, animalType domain.AnimalType, key string) error {
+	filter := bson.M{"type": animalType, "attributes." + key: bson.M{"$exists": true}}
+	change := bson.M{"$unset": bson.M{"attributes." + key: ""}}
+
+	if _, err := m.animalColl.UpdateMany(ctx, filter, change); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// CountMissingAttribute counts the animals of a type, trashed ones included,
+// that have no value for key.
+func (m *mongoAnimalRepository) CountMissingAttribute(ctx context.Context, animalType domain.AnimalType, key string) (int64, error) {
+	return m.animalColl.CountDocuments(ctx, bson.M{"type": animalType, "attributes." + key: nil})
+}
@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/dspeirs7/animals/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// identifiersInUse checks the animal's identifiers against every
+// identifier of the other animals, trashed ones included, so a scan only
+// ever matches one animal. The unique indexes cover each field on its own.
+func (m *mongoAnimalRepository) identifiersInUse(ctx context.Context, animal domain.Animal) error {
+	values := bson.A{}
+	for _, v := range []string{animal.Microchip, animal.LegBand, animal.CollarTag} {
+		if v != "" {
+			values = append(values, v)
+		}
+	}
+
+	if len(values) == 0 {
+		return nil
+	}
+
+	filter := bson.M{
+		"$or": bson.A{
+			bson.M{"microchip": bson.M{"$in": values}},
+			bson.M{"legBand": bson.M{"$in": values}},
+			bson.M{"collarTag": bson.M{"$in": values}},
+		},
+	}
+	if !animal.Id.IsZero() {
+		filter["_id"] = bson.M{"$ne": animal.Id}
+	}
+
+	count, err := m.animalColl.CountDocuments(ctx, filter, options.Count().SetLimit(1))
+	if err != nil {
+		return err
+	}
+
+	if count > 0 {
+		return domain.ErrIdentifierInUse
+	}
+
+	return nil
+}
+
+func (m *mongoAnimalRepository) FindByIdentifier(ctx context.Context, id string) (*domain.Animal, error) {
+	filter := bson.M{
+		"$or": bson.A{
+			bson.M{"microchip": domain.NormalizeMicrochip(id)},
+			bson.M{"legBand": domain.NormalizeTag(id)},
+			bson.M{"collarTag": domain.NormalizeTag(id)},
+		},
+		"deletedAt": bson.M{"$exists": false},
+	}
+
+	var result domain.Animal
+
+	if err := m.animalColl.FindOne(ctx, filter).Decode(&result); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, domain.ErrNoIdentifier
+		}
+		return nil, err
+	}
+
+	return &result, nil
+}
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/dspeirs7/animals/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var imageUrlFields = []string{
+	"imageUrl",
+	"images.thumbnail",
+	"images.card",
+	"images.full",
+	"photos.url",
+	"photos.images.thumbnail",
+	"photos.images.card",
+	"photos.images.full",
+}
+
+// ImageReferences counts the animals that still use an image, so shared
+// content-addressed files are only removed once nothing points at them.
+func (m *mongoAnimalRepository) ImageReferences(ctx context.Context, url string) (int64, error) {
+	or := bson.A{}
+	for _, field := range imageUrlFields {
+		or = append(or, bson.M{field: url})
+	}
+
+	return m.animalColl.CountDocuments(ctx, bson.M{"$or": or})
+}
+
+func (m *mongoAnimalRepository) ReferencedImageUrls(ctx context.Context) (map[string]bool, error) {
+	projection := bson.M{"imageUrl": 1, "images": 1, "photos.url": 1, "photos.images": 1}
+
+	cursor, err := m.animalColl.Find(ctx, bson.D{}, options.Find().SetProjection(projection))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	urls := map[string]bool{}
+
+	for cursor.Next(ctx) {
+		var animal domain.Animal
+		if err := cursor.Decode(&animal); err != nil {
+			return nil, err
+		}
+
+		if animal.ImageUrl != "" {
+			urls[animal.ImageUrl] = true
+		}
+
+		if animal.Images != nil {
+			for _, url := range animal.Images.URLs() {
+				urls[url] = true
+			}
+		}
+
+		for _, photo := range animal.Photos {
+			for _, url := range photo.URLs() {
+				urls[url] = true
+			}
+		}
+	}
+
+	return urls, cursor.Err()
+}
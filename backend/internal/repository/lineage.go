@@ -0,0 +1,295 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/dspeirs7/animals/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// familyFields is what the lineage lookups keep of each relative.
+var familyFields = []string{"name", "type", "breed", "sex", "birthDate", "images", "sireId", "damId", "parentIds"}
+
+// family walks parentIds with $graphLookup, from id's parents up to its
+// ancestors or from its children down to its descendants. A depth of zero
+// doesn't limit the walk, which stops at animals in the trash unless
+// withTrash is set.
+func (m *mongoAnimalRepository) family(ctx context.Context, id primitive.ObjectID, depth int, ancestors, withTrash bool) ([]*domain.Animal, error) {
+	match := bson.M{"_id": id}
+	lookup := bson.M{
+		"from": m.animalColl.Name(),
+		"as":   "family",
+	}
+
+	if !withTrash {
+		match["deletedAt"] = bson.M{"$exists": false}
+		lookup["restrictSearchWithMatch"] = bson.M{"deletedAt": bson.M{"$exists": false}}
+	}
+
+	if ancestors {
+		lookup["startWith"] = "$parentIds"
+		lookup["connectFromField"] = "parentIds"
+		lookup["connectToField"] = "_id"
+	} else {
+		lookup["startWith"] = "$_id"
+		lookup["connectFromField"] = "_id"
+		lookup["connectToField"] = "parentIds"
+	}
+
+	if depth > 0 {
+		lookup["maxDepth"] = depth - 1
+	}
+
+	project := bson.M{"_id": 0, "family._id": 1}
+	for _, field := range familyFields {
+		project["family."+field] = 1
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$graphLookup", Value: lookup}},
+		{{Key: "$project", Value: project}},
+	}
+
+	cursor, err := m.animalColl.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []struct {
+		Family []*domain.Animal `bson:"family"`
+	}
+
+	if err = cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+
+	if len(results) == 0 {
+		return nil, domain.ErrAnimalNotFound
+	}
+
+	return results[0].Family, nil
+}
+
+func (m *mongoAnimalRepository) Ancestors(ctx context.Context, id primitive.ObjectID, depth int) ([]*domain.Animal, error) {
+	return m.family(ctx, id, depth, true, false)
+}
+
+func (m *mongoAnimalRepository) Descendants(ctx context.Context, id primitive.ObjectID, depth int) ([]*domain.Animal, error) {
+	animals, err := m.family(ctx, id, depth, false, false)
+	if errors.Is(err, domain.ErrAnimalNotFound) {
+		// An animal that isn't saved yet has no descendants.
+		return nil, nil
+	}
+	return animals, err
+}
+
+// DescendantIds walks every descendant, those in the trash included, since
+// they come back into the family when restored.
+func (m *mongoAnimalRepository) DescendantIds(ctx context.Context, id primitive.ObjectID) ([]primitive.ObjectID, error) {
+	animals, err := m.family(ctx, id, 0, false, true)
+	if errors.Is(err, domain.ErrAnimalNotFound) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	ids := make([]primitive.ObjectID, 0, len(animals))
+	for _, animal := range animals {
+		ids = append(ids, animal.Id)
+	}
+
+	return ids, nil
+}
+
+func (m *mongoAnimalRepository) LitterMembers(ctx context.Context, litterId primitive.ObjectID, includeTrashed bool) ([]*domain.Animal, error) {
+	filter := bson.M{"litterId": litterId}
+	if !includeTrashed {
+		filter["deletedAt"] = bson.M{"$exists": false}
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "name", Value: 1}})
+
+	cursor, err := m.animalColl.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	results := []*domain.Animal{}
+
+	if err = cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// SetLitterParents gives every member of the litter its sire and dam.
+func (m *mongoAnimalRepository) SetLitterParents(ctx context.Context, litter *domain.Litter) error {
+	parents := domain.Animal{SireId: litter.SireId, DamId: litter.DamId}
+	parents.SyncParents()
+
+	set := bson.M{}
+	unset := bson.M{}
+
+	if parents.SireId != nil {
+		set["sireId"] = parents.SireId
+	} else {
+		unset["sireId"] = ""
+	}
+
+	if parents.DamId != nil {
+		set["damId"] = parents.DamId
+	} else {
+		unset["damId"] = ""
+	}
+
+	if len(parents.ParentIds) > 0 {
+		set["parentIds"] = parents.ParentIds
+	} else {
+		unset["parentIds"] = ""
+	}
+
+	update := bson.M{}
+	if len(set) > 0 {
+		update["$set"] = set
+	}
+	if len(unset) > 0 {
+		update["$unset"] = unset
+	}
+
+	_, err := m.animalColl.UpdateMany(ctx, bson.M{"litterId": litter.Id}, update)
+	return err
+}
+
+func (m *mongoAnimalRepository) ClearLitter(ctx context.Context, litterId primitive.ObjectID) error {
+	_, err := m.animalColl.UpdateMany(ctx, bson.M{"litterId": litterId}, bson.M{"$unset": bson.M{"litterId": ""}})
+	return err
+}
+
+// RemoveParent forgets a purged animal as the sire or dam of its children.
+func (m *mongoAnimalRepository) RemoveParent(ctx context.Context, parentId primitive.ObjectID) error {
+	if _, err := m.animalColl.UpdateMany(ctx, bson.M{"sireId": parentId}, bson.M{"$unset": bson.M{"sireId": ""}}); err != nil {
+		return err
+	}
+
+	if _, err := m.animalColl.UpdateMany(ctx, bson.M{"damId": parentId}, bson.M{"$unset": bson.M{"damId": ""}}); err != nil {
+		return err
+	}
+
+	_, err := m.animalColl.UpdateMany(ctx, bson.M{"parentIds": parentId}, bson.M{"$pull": bson.M{"parentIds": parentId}})
+	return err
+}
+
+type litterRepository struct {
+	litterColl *mongo.Collection
+}
+
+func NewLitterRepository(litterColl *mongo.Collection) domain.LitterRepository {
+	return &litterRepository{litterColl: litterColl}
+}
+
+func (m *litterRepository) List(ctx context.Context, animalType domain.AnimalType) ([]*domain.Litter, error) {
+	filter := bson.M{}
+	if animalType != 0 {
+		filter["type"] = animalType
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "birthDate", Value: -1}})
+
+	cursor, err := m.litterColl.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	results := []*domain.Litter{}
+
+	if err = cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+func (m *litterRepository) GetById(ctx context.Context, id string) (*domain.Litter, error) {
+	objectId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var result domain.Litter
+
+	if err := m.litterColl.FindOne(ctx, bson.M{"_id": objectId}).Decode(&result); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, domain.ErrLitterNotFound
+		}
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+func (m *litterRepository) Insert(ctx context.Context, litter domain.Litter) (*domain.Litter, error) {
+	litter.Id = primitive.NilObjectID
+
+	result, err := m.litterColl.InsertOne(ctx, litter)
+	if err != nil {
+		return nil, err
+	}
+
+	litter.Id = result.InsertedID.(primitive.ObjectID)
+
+	return &litter, nil
+}
+
+func (m *litterRepository) Update(ctx context.Context, id string, litter domain.Litter) error {
+	objectId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+
+	litter.Id = objectId
+
+	result, err := m.litterColl.ReplaceOne(ctx, bson.M{"_id": objectId}, litter)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return domain.ErrLitterNotFound
+	}
+
+	return nil
+}
+
+func (m *litterRepository) Delete(ctx context.Context, id string) error {
+	objectId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+
+	result, err := m.litterColl.DeleteOne(ctx, bson.M{"_id": objectId})
+	if err != nil {
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		return domain.ErrLitterNotFound
+	}
+
+	return nil
+}
+
+// RemoveParent forgets a purged animal as the sire or dam of its litters.
+func (m *litterRepository) RemoveParent(ctx context.Context, parentId primitive.ObjectID) error {
+	if _, err := m.litterColl.UpdateMany(ctx, bson.M{"sireId": parentId}, bson.M{"$unset": bson.M{"sireId": ""}}); err != nil {
+		return err
+	}
+
+	_, err := m.litterColl.UpdateMany(ctx, bson.M{"damId": parentId}, bson.M{"$unset": bson.M{"damId": ""}})
+	return err
+}
@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/dspeirs7/animals/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type lockRepository struct {
+	lockColl *mongo.Collection
+}
+
+// NewLockRepository keeps leases in lockColl. Mongo's TTL monitor removes
+// them once they expire.
+func NewLockRepository(ctx context.Context, lockColl *mongo.Collection) (domain.LockRepository, error) {
+	index := mongo.IndexModel{
+		Keys:    bson.D{{Key: "expiry", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	}
+
+	if _, err := lockColl.Indexes().CreateOne(ctx, index); err != nil {
+		return nil, err
+	}
+
+	return &lockRepository{lockColl: lockColl}, nil
+}
+
+// Acquire upserts the lease only when it is missing or expired; a live lease
+// makes the upsert collide on _id.
+func (m *lockRepository) Acquire(ctx context.Context, name string, expiry time.Time) error {
+	filter := bson.M{"_id": name, "expiry": bson.M{"$lte": time.Now()}}
+	update := bson.M{"$set": bson.M{"expiry": expiry}}
+
+	if _, err := m.lockColl.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true)); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return domain.ErrLockHeld
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (m *lockRepository) Release(ctx context.Context, name string) error {
+	if _, err := m.lockColl.DeleteOne(ctx, bson.M{"_id": name}); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,206 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/dspeirs7/animals/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type medicalRepository struct {
+	medicalColl *mongo.Collection
+}
+
+func NewMedicalRepository(ctx context.Context, medicalColl *mongo.Collection) (domain.MedicalRepository, error) {
+	indexes := []mongo.IndexModel{
+		{Keys: bson.D{{Key: "animalId", Value: 1}, {Key: "date", Value: -1}}},
+		{Keys: bson.D{{Key: "kind", Value: 1}, {Key: "endDate", Value: 1}}},
+	}
+
+	if _, err := medicalColl.Indexes().CreateMany(ctx, indexes); err != nil {
+		return nil, err
+	}
+
+	return &medicalRepository{medicalColl: medicalColl}, nil
+}
+
+func (m *medicalRepository) ForAnimal(ctx context.Context, animalId primitive.ObjectID) ([]*domain.MedicalRecord, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "date", Value: -1}, {Key: "_id", Value: -1}})
+
+	cursor, err := m.medicalColl.Find(ctx, bson.M{"animalId": animalId}, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	results := []*domain.MedicalRecord{}
+
+	if err = cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+func (m *medicalRepository) GetById(ctx context.Context, id string) (*domain.MedicalRecord, error) {
+	objectId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var result domain.MedicalRecord
+
+	if err := m.medicalColl.FindOne(ctx, bson.M{"_id": objectId}).Decode(&result); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, domain.ErrMedicalRecordNotFound
+		}
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+func (m *medicalRepository) Insert(ctx context.Context, record domain.MedicalRecord) (*domain.MedicalRecord, error) {
+	record.Id = primitive.NilObjectID
+
+	result, err := m.medicalColl.InsertOne(ctx, record)
+	if err != nil {
+		return nil, err
+	}
+
+	record.Id = result.InsertedID.(primitive.ObjectID)
+
+	return &record, nil
+}
+
+func (m *medicalRepository) Update(ctx context.Context, id string, record domain.MedicalRecord) error {
+	objectId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+
+	record.Id = objectId
+
+	result, err := m.medicalColl.ReplaceOne(ctx, bson.M{"_id": objectId}, record)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return domain.ErrMedicalRecordNotFound
+	}
+
+	return nil
+}
+
+func (m *medicalRepository) Delete(ctx context.Context, id string) error {
+	objectId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+
+	result, err := m.medicalColl.DeleteOne(ctx, bson.M{"_id": objectId})
+	if err != nil {
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		return domain.ErrMedicalRecordNotFound
+	}
+
+	return nil
+}
+
+func (m *medicalRepository) DeleteForAnimal(ctx context.Context, animalId primitive.ObjectID) error {
+	_, err := m.medicalColl.DeleteMany(ctx, bson.M{"animalId": animalId})
+	return err
+}
+
+// UnlinkVisit clears VisitId on the diagnoses and medications that came out
+// of a deleted visit.
+func (m *medicalRepository) UnlinkVisit(ctx context.Context, visitId primitive.ObjectID) error {
+	_, err := m.medicalColl.UpdateMany(ctx, bson.M{"visitId": visitId}, bson.M{"$unset": bson.M{"visitId": ""}})
+	return err
+}
+
+// courseFilter matches the medication courses running at some point
+// between from and to. EndDate is the last day of a course, see
+// MedicalRecord.Active.
+func courseFilter(from, to time.Time) bson.M {
+	return bson.M{
+		"kind": domain.MedicalMedication,
+		"date": bson.M{"$lte": to},
+		"$or": bson.A{
+			bson.M{"endDate": bson.M{"$exists": false}},
+			bson.M{"endDate": bson.M{"$gt": from.AddDate(0, 0, -1)}},
+		},
+	}
+}
+
+func (m *medicalRepository) ActiveMedications(ctx context.Context, from, to time.Time, types []domain.AnimalType) ([]*domain.ActiveMedication, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: courseFilter(from, to)}},
+		{{Key: "$lookup", Value: bson.M{
+			"from":         "animals",
+			"localField":   "animalId",
+			"foreignField": "_id",
+			"as":           "animal",
+		}}},
+		{{Key: "$unwind", Value: "$animal"}},
+	}
+
+	// Animals in the trash or out of our care get no doses.
+	match := bson.M{
+		"animal.status":    statusFilter(domain.ActiveStatuses),
+		"animal.deletedAt": bson.M{"$exists": false},
+	}
+	if len(types) > 0 {
+		match["animal.type"] = bson.M{"$in": types}
+	}
+	pipeline = append(pipeline, bson.D{{Key: "$match", Value: match}})
+
+	pipeline = append(pipeline,
+		bson.D{{Key: "$sort", Value: bson.D{{Key: "animal.name", Value: 1}, {Key: "drug", Value: 1}}}},
+		bson.D{{Key: "$project", Value: bson.M{
+			"_id":        0,
+			"animalId":   1,
+			"animalName": "$animal.name",
+			"animalType": "$animal.type",
+			"medication": "$$ROOT",
+		}}},
+		bson.D{{Key: "$unset", Value: "medication.animal"}},
+	)
+
+	cursor, err := m.medicalColl.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+
+	results := []*domain.ActiveMedication{}
+
+	if err = cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+func (m *medicalRepository) AnimalsOnMedication(ctx context.Context, at time.Time) ([]primitive.ObjectID, error) {
+	values, err := m.medicalColl.Distinct(ctx, "animalId", courseFilter(at, at))
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]primitive.ObjectID, 0, len(values))
+	for _, v := range values {
+		if id, ok := v.(primitive.ObjectID); ok {
+			ids = append(ids, id)
+		}
+	}
+
+	return ids, nil
+}
@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"os"
+	"time"
 
 	"github.com/dspeirs7/animals/internal/domain"
 	"github.com/dspeirs7/animals/internal/log"
@@ -11,7 +12,6 @@ import (
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"go.uber.org/zap"
-	"golang.org/x/crypto/bcrypt"
 )
 
 func GetDB(ctx context.Context) *mongo.Client {
@@ -42,40 +42,71 @@ func GetDB(ctx context.Context) *mongo.Client {
 	}
 
 	createAdminUser(client.Database("animals").Collection("users"), adminPassword, logger)
+	createAnimalIndexes(client.Database("animals").Collection("animals"), logger)
 
 	return client
 }
 
+func createAnimalIndexes(animalColl *mongo.Collection, logger *zap.Logger) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	indexes := []mongo.IndexModel{
+		{Keys: bson.D{{Key: "parentIds", Value: 1}}},
+		{Keys: bson.D{{Key: "litterId", Value: 1}}, Options: options.Index().SetSparse(true)},
+		{Keys: bson.D{{Key: "microchip", Value: 1}}, Options: options.Index().SetUnique(true).SetSparse(true)},
+		{Keys: bson.D{{Key: "legBand", Value: 1}}, Options: options.Index().SetUnique(true).SetSparse(true)},
+		{Keys: bson.D{{Key: "collarTag", Value: 1}}, Options: options.Index().SetUnique(true).SetSparse(true)},
+		{Keys: bson.D{{Key: "statusHistory.contactId", Value: 1}}, Options: options.Index().SetSparse(true)},
+		{Keys: bson.D{{Key: "deletedAt", Value: 1}}, Options: options.Index().SetSparse(true)},
+	}
+
+	if _, err := animalColl.Indexes().CreateMany(ctx, indexes); err != nil {
+		logger.Fatal("error creating animal index", zap.Error(err))
+	}
+}
+
 func createAdminUser(userColl *mongo.Collection, adminPassword string, logger *zap.Logger) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	cursor, err := userColl.Find(ctx, bson.D{})
-	if err != nil {
-		logger.Fatal("error getting user collection", zap.Error(err))
+	indexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "username", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "calendarToken", Value: 1}},
+			Options: options.Index().SetUnique(true).SetSparse(true),
+		},
 	}
 
-	var users []domain.User
+	if _, err := userColl.Indexes().CreateMany(ctx, indexes); err != nil {
+		logger.Fatal("error creating user index", zap.Error(err))
+	}
 
-	err = cursor.All(context.Background(), &users)
+	// Accounts created before roles existed are the single shared admin.
+	if _, err := userColl.UpdateMany(ctx, bson.M{"username": "admin", "role": bson.M{"$exists": false}}, bson.M{"$set": bson.M{"role": domain.RoleAdmin}}); err != nil {
+		logger.Fatal("error migrating admin role", zap.Error(err))
+	}
+
+	count, err := userColl.CountDocuments(ctx, bson.D{})
 	if err != nil {
-		cancel()
-		logger.Fatal("error getting users", zap.Error(err))
+		logger.Fatal("error getting user collection", zap.Error(err))
 	}
 
-	if len(users) == 1 {
+	if count > 0 {
 		return
-	} else if len(users) > 1 {
-		userColl.DeleteMany(ctx, nil)
 	}
 
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(adminPassword), bcrypt.DefaultCost)
+	hashedPassword, err := domain.HashPassword(adminPassword)
 	if err != nil {
-		cancel()
 		logger.Fatal("not able to hash password", zap.Error(err))
 	}
 
-	user := domain.User{Username: "admin", Password: string(hashedPassword)}
+	user := domain.User{Username: "admin", Password: hashedPassword, Role: domain.RoleAdmin, CreatedAt: time.Now()}
 
-	userColl.InsertOne(ctx, &user)
+	if _, err := userColl.InsertOne(ctx, &user); err != nil {
+		logger.Fatal("error creating admin user", zap.Error(err))
+	}
 }
@@ -0,0 +1,221 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/dspeirs7/animals/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// syncPrimaryPhoto keeps exactly one primary photo, falling back to the
+// first, and mirrors it onto imageUrl and images for clients that predate
+// the gallery. It only touches animals whose gallery has been materialized.
+var syncPrimaryPhoto = mongo.Pipeline{
+	{{Key: "$set", Value: bson.M{"primary": bson.M{"$ifNull": bson.A{
+		bson.M{"$arrayElemAt": bson.A{bson.M{"$filter": bson.M{"input": "$photos", "cond": "$$this.isPrimary"}}, 0}},
+		bson.M{"$arrayElemAt": bson.A{"$photos", 0}},
+	}}}}},
+	{{Key: "$set", Value: bson.M{
+		"photos": bson.M{"$map": bson.M{"input": "$photos", "in": bson.M{"$mergeObjects": bson.A{
+			"$$this",
+			bson.M{"isPrimary": bson.M{"$eq": bson.A{"$$this.id", "$primary.id"}}},
+		}}}},
+		"imageUrl": bson.M{"$ifNull": bson.A{"$primary.url", "$$REMOVE"}},
+		"images":   bson.M{"$ifNull": bson.A{"$primary.images", "$$REMOVE"}},
+	}}},
+	{{Key: "$unset", Value: "primary"}},
+}
+
+func (m *mongoAnimalRepository) syncPrimaryPhoto(ctx context.Context, objectId primitive.ObjectID) error {
+	filter := bson.M{"_id": objectId, "photos": bson.M{"$exists": true}}
+
+	if _, err := m.animalColl.UpdateOne(ctx, filter, syncPrimaryPhoto); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// MaterializeLegacyPhoto saves an ImageUrl uploaded before galleries
+// existed as the animal's first photo, unless another request already has.
+func (m *mongoAnimalRepository) MaterializeLegacyPhoto(ctx context.Context, id string, photo domain.Photo) error {
+	objectId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+
+	filter := bson.M{"_id": objectId, "photos": bson.M{"$exists": false}, "imageUrl": photo.Url}
+	change := bson.M{"$set": bson.M{"photos": []domain.Photo{photo}}}
+
+	if _, err := m.animalColl.UpdateOne(ctx, filter, change); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (m *mongoAnimalRepository) AddPhoto(ctx context.Context, id string, photo domain.Photo) error {
+	objectId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+
+	filter := bson.M{"_id": objectId, "deletedAt": bson.M{"$exists": false}}
+	change := bson.M{"$push": bson.M{"photos": photo}}
+
+	result, err := m.animalColl.UpdateOne(ctx, filter, change)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return domain.ErrAnimalNotFound
+	}
+
+	if photo.IsPrimary {
+		return m.SetPrimaryPhoto(ctx, id, photo.Id)
+	}
+
+	return m.syncPrimaryPhoto(ctx, objectId)
+}
+
+// UpdatePhoto saves the photo's files, caption and date, leaving its place
+// in the gallery and whether it is primary alone.
+func (m *mongoAnimalRepository) UpdatePhoto(ctx context.Context, id string, photo domain.Photo) error {
+	objectId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+
+	set := bson.M{"photos.$[photo].url": photo.Url}
+	unset := bson.M{}
+
+	if photo.Images != nil {
+		set["photos.$[photo].images"] = photo.Images
+	} else {
+		unset["photos.$[photo].images"] = ""
+	}
+
+	if photo.Caption != "" {
+		set["photos.$[photo].caption"] = photo.Caption
+	} else {
+		unset["photos.$[photo].caption"] = ""
+	}
+
+	if photo.TakenAt != 0 {
+		set["photos.$[photo].takenAt"] = photo.TakenAt
+	} else {
+		unset["photos.$[photo].takenAt"] = ""
+	}
+
+	change := bson.M{"$set": set}
+	if len(unset) > 0 {
+		change["$unset"] = unset
+	}
+
+	filter := bson.M{"_id": objectId, "photos.id": photo.Id}
+	opts := options.Update().SetArrayFilters(options.ArrayFilters{Filters: bson.A{bson.M{"photo.id": photo.Id}}})
+
+	result, err := m.animalColl.UpdateOne(ctx, filter, change, opts)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return domain.ErrPhotoNotFound
+	}
+
+	return m.syncPrimaryPhoto(ctx, objectId)
+}
+
+func (m *mongoAnimalRepository) SetPrimaryPhoto(ctx context.Context, id string, photoId primitive.ObjectID) error {
+	objectId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+
+	filter := bson.M{"_id": objectId, "photos.id": photoId}
+	change := bson.M{"$set": bson.M{"photos.$[primary].isPrimary": true, "photos.$[other].isPrimary": false}}
+	opts := options.Update().SetArrayFilters(options.ArrayFilters{Filters: bson.A{
+		bson.M{"primary.id": photoId},
+		bson.M{"other.id": bson.M{"$ne": photoId}},
+	}})
+
+	result, err := m.animalColl.UpdateOne(ctx, filter, change, opts)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return domain.ErrPhotoNotFound
+	}
+
+	return m.syncPrimaryPhoto(ctx, objectId)
+}
+
+func (m *mongoAnimalRepository) RemovePhoto(ctx context.Context, id string, photoId primitive.ObjectID) error {
+	objectId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+
+	filter := bson.M{"_id": objectId, "photos.id": photoId}
+	change := bson.M{"$pull": bson.M{"photos": bson.M{"id": photoId}}}
+
+	result, err := m.animalColl.UpdateOne(ctx, filter, change)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return domain.ErrPhotoNotFound
+	}
+
+	return m.syncPrimaryPhoto(ctx, objectId)
+}
+
+// ReorderPhotos only applies when ids still lists exactly the animal's
+// photos, and moves the stored photos rather than rewriting them.
+func (m *mongoAnimalRepository) ReorderPhotos(ctx context.Context, id string, ids []primitive.ObjectID) error {
+	objectId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+
+	// An empty gallery has no order to change, and $all of nothing matches
+	// no document.
+	if len(ids) == 0 {
+		return nil
+	}
+
+	order := bson.A{}
+	for _, photoId := range ids {
+		order = append(order, photoId)
+	}
+
+	filter := bson.M{"_id": objectId, "photos": bson.M{"$size": len(ids)}, "photos.id": bson.M{"$all": order}}
+	change := mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{"photos": bson.M{"$map": bson.M{
+			"input": bson.M{"$literal": order},
+			"as":    "id",
+			"in": bson.M{"$arrayElemAt": bson.A{
+				bson.M{"$filter": bson.M{"input": "$photos", "cond": bson.M{"$eq": bson.A{"$$this.id", "$$id"}}}},
+				0,
+			}},
+		}}}}},
+	}
+
+	result, err := m.animalColl.UpdateOne(ctx, filter, change)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return domain.ErrPhotoOrder
+	}
+
+	return nil
+}
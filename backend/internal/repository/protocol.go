@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/dspeirs7/animals/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type protocolRepository struct {
+	protocolColl *mongo.Collection
+}
+
+func NewProtocolRepository(protocolColl *mongo.Collection) domain.ProtocolRepository {
+	return &protocolRepository{protocolColl: protocolColl}
+}
+
+func (m *protocolRepository) GetAll(ctx context.Context, animalType domain.AnimalType) ([]*domain.VaccinationProtocol, error) {
+	filter := bson.M{}
+	if animalType != 0 {
+		filter["type"] = animalType
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "type", Value: 1}, {Key: "name", Value: 1}})
+
+	cursor, err := m.protocolColl.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	results := []*domain.VaccinationProtocol{}
+
+	if err = cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+func (m *protocolRepository) GetById(ctx context.Context, id string) (*domain.VaccinationProtocol, error) {
+	objectId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, domain.ErrInvalidProtocolId
+	}
+
+	var result domain.VaccinationProtocol
+
+	if err := m.protocolColl.FindOne(ctx, bson.M{"_id": objectId}).Decode(&result); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, domain.ErrProtocolNotFound
+		}
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+func (m *protocolRepository) Insert(ctx context.Context, protocol domain.VaccinationProtocol) (*domain.VaccinationProtocol, error) {
+	protocol.Id = primitive.NilObjectID
+
+	result, err := m.protocolColl.InsertOne(ctx, protocol)
+	if err != nil {
+		return nil, err
+	}
+
+	protocol.Id = result.InsertedID.(primitive.ObjectID)
+
+	return &protocol, nil
+}
+
+func (m *protocolRepository) Update(ctx context.Context, id string, protocol domain.VaccinationProtocol) error {
+	objectId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return domain.ErrInvalidProtocolId
+	}
+
+	protocol.Id = objectId
+
+	result, err := m.protocolColl.ReplaceOne(ctx, bson.M{"_id": objectId}, protocol)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return domain.ErrProtocolNotFound
+	}
+
+	return nil
+}
+
+func (m *protocolRepository) Delete(ctx context.Context, id string) error {
+	objectId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return domain.ErrInvalidProtocolId
+	}
+
+	result, err := m.protocolColl.DeleteOne(ctx, bson.M{"_id": objectId})
+	if err != nil {
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		return domain.ErrProtocolNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,205 @@
+package repository
+
+import (
+	"encoding/base64"
+	"regexp"
+	"strings"
+
+	"github.com/dspeirs7/animals/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type sortField struct {
+	field  string
+	invert bool
+}
+
+var animalSortFields = map[string]sortField{
+	"":          {field: "_id"},
+	"id":        {field: "_id"},
+	"name":      {field: "name"},
+	"type":      {field: "type"},
+	"breed":     {field: "breed"},
+	"birthDate": {field: "birthDate"},
+	// The oldest animals have the earliest birth dates.
+	"age": {field: "birthDate", invert: true},
+}
+
+type animalCursor struct {
+	Value interface{}        `bson:"v"`
+	Id    primitive.ObjectID `bson:"id"`
+}
+
+func animalFilter(query domain.AnimalQuery) bson.D {
+	filter := bson.D{{Key: "deletedAt", Value: bson.M{"$exists": query.Trashed}}}
+
+	if len(query.Types) > 0 {
+		filter = append(filter, bson.E{Key: "type", Value: bson.M{"$in": query.Types}})
+	}
+
+	if len(query.Breeds) > 0 {
+		filter = append(filter, bson.E{Key: "breed", Value: bson.M{"$in": query.Breeds}})
+	}
+
+	if query.NamePrefix != "" {
+		pattern := "^" + regexp.QuoteMeta(query.NamePrefix)
+		filter = append(filter, bson.E{Key: "name", Value: primitive.Regex{Pattern: pattern, Options: "i"}})
+	}
+
+	born := bson.M{}
+	if !query.BornAfter.IsZero() {
+		born["$gte"] = query.BornAfter
+	}
+	if !query.BornBefore.IsZero() {
+		born["$lte"] = query.BornBefore
+	}
+	if len(born) > 0 {
+		filter = append(filter, bson.E{Key: "birthDate", Value: born})
+	}
+
+	if len(query.Sexes) > 0 {
+		filter = append(filter, bson.E{Key: "sex", Value: bson.M{"$in": query.Sexes}})
+	}
+
+	if len(query.Statuses) > 0 {
+		filter = append(filter, bson.E{Key: "status", Value: statusFilter(query.Statuses)})
+	}
+
+	if query.Altered != nil {
+		if *query.Altered {
+			filter = append(filter, bson.E{Key: "altered", Value: true})
+		} else {
+			filter = append(filter, bson.E{Key: "altered", Value: bson.M{"$ne": true}})
+		}
+	}
+
+	if query.Vaccinated != nil {
+		filter = append(filter, bson.E{Key: "vaccinations.dateGiven", Value: bson.M{"$exists": *query.Vaccinated}})
+	}
+
+	attributes := make(map[string]bson.M)
+	var keys []string
+	for _, a := range query.Attributes {
+		key := "attributes." + a.Key
+		condition, ok := attributes[key]
+		if !ok {
+			condition = bson.M{}
+			attributes[key] = condition
+			keys = append(keys, key)
+		}
+
+		switch a.Op {
+		case domain.AttributeIn:
+			condition["$in"] = a.Values
+		case domain.AttributeMin:
+			condition["$gte"] = a.Values[0]
+		case domain.AttributeMax:
+			condition["$lte"] = a.Values[0]
+		}
+	}
+	for _, key := range keys {
+		filter = append(filter, bson.E{Key: key, Value: attributes[key]})
+	}
+
+	ids := bson.M{}
+	if query.Ids != nil {
+		ids["$in"] = query.Ids
+	}
+	if len(query.ExcludeIds) > 0 {
+		ids["$nin"] = query.ExcludeIds
+	}
+	if len(ids) > 0 {
+		filter = append(filter, bson.E{Key: "_id", Value: ids})
+	}
+
+	return filter
+}
+
+func animalSort(sort string) (string, int, error) {
+	direction := 1
+	if strings.HasPrefix(sort, "-") {
+		direction = -1
+		sort = strings.TrimPrefix(sort, "-")
+	}
+
+	field, ok := animalSortFields[sort]
+	if !ok {
+		return "", 0, domain.ErrInvalidSort
+	}
+
+	if field.invert {
+		direction = -direction
+	}
+
+	return field.field, direction, nil
+}
+
+func cursorFilter(encoded string, field string, direction int) (bson.D, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, domain.ErrInvalidCursor
+	}
+
+	var cursor animalCursor
+	if err := bson.Unmarshal(raw, &cursor); err != nil {
+		return nil, domain.ErrInvalidCursor
+	}
+
+	op := "$gt"
+	if direction < 0 {
+		op = "$lt"
+	}
+
+	if field == "_id" {
+		return bson.D{{Key: "_id", Value: bson.M{op: cursor.Id}}}, nil
+	}
+
+	// Missing values sort before everything else, and can't be compared
+	// with $gt or $lt.
+	if cursor.Value == nil {
+		if direction < 0 {
+			return bson.D{{Key: field, Value: nil}, {Key: "_id", Value: bson.M{op: cursor.Id}}}, nil
+		}
+
+		return bson.D{{Key: "$or", Value: bson.A{
+			bson.M{field: bson.M{"$ne": nil}},
+			bson.M{field: nil, "_id": bson.M{op: cursor.Id}},
+		}}}, nil
+	}
+
+	after := bson.A{
+		bson.M{field: bson.M{op: cursor.Value}},
+		bson.M{field: cursor.Value, "_id": bson.M{op: cursor.Id}},
+	}
+
+	// Descending, the missing values all come after the last page's value.
+	if direction < 0 {
+		after = append(after, bson.M{field: nil})
+	}
+
+	return bson.D{{Key: "$or", Value: after}}, nil
+}
+
+func encodeCursor(last *domain.Animal, field string) string {
+	cursor := animalCursor{Id: last.Id}
+
+	// Zero values are omitted from the document, so they page as missing.
+	switch {
+	case field == "name" && last.Name != "":
+		cursor.Value = last.Name
+	case field == "type" && last.Type != 0:
+		cursor.Value = last.Type
+	case field == "breed" && last.Breed != 0:
+		cursor.Value = last.Breed
+	case field == "birthDate" && last.BirthDate != 0:
+		cursor.Value = last.BirthDate
+	}
+
+	raw, err := bson.Marshal(cursor)
+	if err != nil {
+		return ""
+	}
+
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
@@ -0,0 +1,317 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dspeirs7/animals/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// matchDoc evaluates the subset of Mongo's query language cursorFilter
+// produces against a stored document.
+func matchDoc(t *testing.T, doc bson.M, filter interface{}) bool {
+	t.Helper()
+
+	var elems []bson.E
+	switch f := filter.(type) {
+	case bson.D:
+		elems = f
+	case bson.M:
+		for k, v := range f {
+			elems = append(elems, bson.E{Key: k, Value: v})
+		}
+	default:
+		t.Fatalf("unsupported filter %T", filter)
+	}
+
+	for _, e := range elems {
+		switch e.Key {
+		case "$and", "$or":
+			any := false
+			all := true
+			for _, sub := range e.Value.(bson.A) {
+				if matchDoc(t, doc, sub) {
+					any = true
+				} else {
+					all = false
+				}
+			}
+			if e.Key == "$and" && !all || e.Key == "$or" && !any {
+				return false
+			}
+			continue
+		}
+
+		value, present := doc[e.Key]
+		if !present {
+			value = nil
+		}
+
+		ops, isOps := e.Value.(bson.M)
+		if !isOps {
+			if compareValues(value, e.Value) != 0 {
+				return false
+			}
+			continue
+		}
+
+		for op, operand := range ops {
+			// Like Mongo, $gt and $lt never match missing or null values.
+			c := compareValues(value, operand)
+			switch op {
+			case "$gt":
+				if value == nil || c <= 0 {
+					return false
+				}
+			case "$lt":
+				if value == nil || c >= 0 {
+					return false
+				}
+			case "$ne":
+				if c == 0 {
+					return false
+				}
+			default:
+				t.Fatalf("unsupported operator %s", op)
+			}
+		}
+	}
+
+	return true
+}
+
+// compareValues orders values the way the fields used here sort in Mongo,
+// with missing and null values first.
+func compareValues(a, b interface{}) int {
+	a, b = normalize(a), normalize(b)
+
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	}
+
+	switch av := a.(type) {
+	case int64:
+		bv := b.(int64)
+		switch {
+		case av < bv:
+			return -1
+		case av > bv:
+			return 1
+		}
+		return 0
+	case string:
+		return strings.Compare(av, b.(string))
+	}
+
+	panic(fmt.Sprintf("unsupported value %T", a))
+}
+
+func normalize(v interface{}) interface{} {
+	switch n := v.(type) {
+	case int:
+		return int64(n)
+	case int32:
+		return int64(n)
+	case domain.AnimalType:
+		return int64(n)
+	case domain.AnimalBreed:
+		return int64(n)
+	case primitive.DateTime:
+		return int64(n)
+	case primitive.ObjectID:
+		return n.Hex()
+	}
+	return v
+}
+
+func storedDoc(t *testing.T, animal *domain.Animal) bson.M {
+	t.Helper()
+
+	raw, err := bson.Marshal(animal)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var doc bson.M
+	if err := bson.Unmarshal(raw, &doc); err != nil {
+		t.Fatal(err)
+	}
+	return doc
+}
+
+func pagingAnimals() []*domain.Animal {
+	day := func(d int) primitive.DateTime {
+		return primitive.NewDateTimeFromTime(time.Date(2020, 1, d, 0, 0, 0, 0, time.UTC))
+	}
+
+	specs := []struct {
+		name  string
+		born  primitive.DateTime
+		breed domain.AnimalBreed
+	}{
+		{"Ada", day(3), 10},
+		{"", day(1), 0},
+		{"Bea", 0, 11},
+		{"Cy", day(3), 10},
+		{"", 0, 0},
+		{"Dot", day(2), 0},
+		{"Eve", 0, 10},
+		{"Bea", day(5), 11},
+	}
+
+	animals := make([]*domain.Animal, len(specs))
+	for i, s := range specs {
+		// Ids ascend in creation order like real ObjectIDs.
+		id := primitive.NewObjectIDFromTimestamp(time.Unix(int64(1_600_000_000+i), 0))
+		animals[i] = &domain.Animal{Id: id, Name: s.name, BirthDate: s.born, Breed: s.breed, Type: domain.CatType}
+	}
+	return animals
+}
+
+func TestCursorPagination(t *testing.T) {
+	animals := pagingAnimals()
+
+	for _, sortParam := range []string{"", "-id", "name", "-name", "breed", "-breed", "birthDate", "-birthDate", "age", "-age"} {
+		for _, limit := range []int{1, 2, 3} {
+			t.Run(fmt.Sprintf("sort=%s/limit=%d", sortParam, limit), func(t *testing.T) {
+				field, direction, err := animalSort(sortParam)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				docs := map[primitive.ObjectID]bson.M{}
+				for _, animal := range animals {
+					docs[animal.Id] = storedDoc(t, animal)
+				}
+
+				ordered := append([]*domain.Animal(nil), animals...)
+				sort.SliceStable(ordered, func(i, k int) bool {
+					c := compareValues(docs[ordered[i].Id][field], docs[ordered[k].Id][field])
+					if c == 0 {
+						c = compareValues(ordered[i].Id, ordered[k].Id)
+					}
+					return c*direction < 0
+				})
+
+				var paged []primitive.ObjectID
+				cursor := ""
+
+				for pages := 0; ; pages++ {
+					if pages > len(animals) {
+						t.Fatal("pagination did not finish")
+					}
+
+					var page []*domain.Animal
+					for _, animal := range ordered {
+						if cursor != "" {
+							after, err := cursorFilter(cursor, field, direction)
+							if err != nil {
+								t.Fatal(err)
+							}
+							if !matchDoc(t, docs[animal.Id], after) {
+								continue
+							}
+						}
+						page = append(page, animal)
+					}
+
+					if len(page) > limit {
+						page = page[:limit]
+						for _, animal := range page {
+							paged = append(paged, animal.Id)
+						}
+						cursor = encodeCursor(page[len(page)-1], field)
+						continue
+					}
+
+					for _, animal := range page {
+						paged = append(paged, animal.Id)
+					}
+					break
+				}
+
+				if len(paged) != len(ordered) {
+					t.Fatalf("paged through %d animals, want %d", len(paged), len(ordered))
+				}
+				for i := range ordered {
+					if paged[i] != ordered[i].Id {
+						t.Fatalf("position %d is %s, want %s", i, paged[i].Hex(), ordered[i].Id.Hex())
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestCursorFilterNullBranch(t *testing.T) {
+	last := &domain.Animal{Id: primitive.NewObjectID(), Name: "Bea"}
+
+	tests := []struct {
+		name      string
+		direction int
+		wantNull  bool
+	}{
+		{"ascending skips missing names", 1, false},
+		{"descending keeps missing names", -1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, err := cursorFilter(encodeCursor(last, "name"), "name", tt.direction)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			unnamed := bson.M{"_id": primitive.NewObjectID()}
+			if got := matchDoc(t, unnamed, filter); got != tt.wantNull {
+				t.Errorf("animal without a name matched = %v, want %v", got, tt.wantNull)
+			}
+		})
+	}
+}
+
+func TestCursorFilterInvalid(t *testing.T) {
+	for _, encoded := range []string{"not base64!", "AAAA", "e30"} {
+		if _, err := cursorFilter(encoded, "name", 1); !errors.Is(err, domain.ErrInvalidCursor) {
+			t.Errorf("cursorFilter(%q) error = %v, want ErrInvalidCursor", encoded, err)
+		}
+	}
+}
+
+func TestAnimalSort(t *testing.T) {
+	tests := []struct {
+		sort      string
+		field     string
+		direction int
+		wantErr   bool
+	}{
+		{"", "_id", 1, false},
+		{"name", "name", 1, false},
+		{"-name", "name", -1, false},
+		{"age", "birthDate", -1, false},
+		{"-age", "birthDate", 1, false},
+		{"weight", "", 0, true},
+	}
+
+	for _, tt := range tests {
+		field, direction, err := animalSort(tt.sort)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("animalSort(%q) error = %v", tt.sort, err)
+			continue
+		}
+		if field != tt.field || direction != tt.direction {
+			t.Errorf("animalSort(%q) = %s %d, want %s %d", tt.sort, field, direction, tt.field, tt.direction)
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/dspeirs7/animals/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type memorySessionStore struct {
+	mu       sync.RWMutex
+	sessions map[string]domain.Session
+}
+
+func NewMemorySessionStore() domain.SessionStore {
+	return &memorySessionStore{sessions: make(map[string]domain.Session)}
+}
+
+func (m *memorySessionStore) Create(_ context.Context, session domain.Session) (string, error) {
+	sessionId, err := domain.NewSessionId()
+	if err != nil {
+		return "", err
+	}
+
+	session.Id = sessionId
+
+	m.mu.Lock()
+	m.sessions[sessionId] = session
+	m.mu.Unlock()
+
+	return sessionId, nil
+}
+
+func (m *memorySessionStore) Get(_ context.Context, sessionId string) (*domain.Session, error) {
+	m.mu.RLock()
+	session, ok := m.sessions[sessionId]
+	m.mu.RUnlock()
+
+	if !ok {
+		return nil, domain.ErrSessionNotFound
+	}
+
+	return &session, nil
+}
+
+func (m *memorySessionStore) Touch(_ context.Context, sessionId string, expiry time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	session, ok := m.sessions[sessionId]
+	if !ok {
+		return domain.ErrSessionNotFound
+	}
+
+	session.Expiry = expiry
+	m.sessions[sessionId] = session
+
+	return nil
+}
+
+func (m *memorySessionStore) Delete(_ context.Context, sessionId string) error {
+	m.mu.Lock()
+	delete(m.sessions, sessionId)
+	m.mu.Unlock()
+
+	return nil
+}
+
+func (m *memorySessionStore) DeleteForUser(_ context.Context, username string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for sessionId, session := range m.sessions {
+		if session.Username == username {
+			delete(m.sessions, sessionId)
+		}
+	}
+
+	return nil
+}
+
+func (m *memorySessionStore) DeleteExpired(_ context.Context) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var deleted int64
+	for sessionId, session := range m.sessions {
+		if session.IsExpired() {
+			delete(m.sessions, sessionId)
+			deleted++
+		}
+	}
+
+	return deleted, nil
+}
+
+type mongoSessionStore struct {
+	sessionColl *mongo.Collection
+}
+
+// NewMongoSessionStore persists sessions so they survive restarts and are
+// shared between replicas. Mongo's TTL monitor removes expired documents.
+func NewMongoSessionStore(ctx context.Context, sessionColl *mongo.Collection) (domain.SessionStore, error) {
+	index := mongo.IndexModel{
+		Keys:    bson.D{{Key: "expiry", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	}
+
+	if _, err := sessionColl.Indexes().CreateOne(ctx, index); err != nil {
+		return nil, err
+	}
+
+	return &mongoSessionStore{sessionColl: sessionColl}, nil
+}
+
+func (m *mongoSessionStore) Create(ctx context.Context, session domain.Session) (string, error) {
+	sessionId, err := domain.NewSessionId()
+	if err != nil {
+		return "", err
+	}
+
+	session.Id = sessionId
+
+	if _, err := m.sessionColl.InsertOne(ctx, session); err != nil {
+		return "", err
+	}
+
+	return sessionId, nil
+}
+
+func (m *mongoSessionStore) Get(ctx context.Context, sessionId string) (*domain.Session, error) {
+	var session domain.Session
+
+	if err := m.sessionColl.FindOne(ctx, bson.M{"_id": sessionId}).Decode(&session); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, domain.ErrSessionNotFound
+		}
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+func (m *mongoSessionStore) Touch(ctx context.Context, sessionId string, expiry time.Time) error {
+	result, err := m.sessionColl.UpdateByID(ctx, sessionId, bson.M{"$set": bson.M{"expiry": expiry}})
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return domain.ErrSessionNotFound
+	}
+
+	return nil
+}
+
+func (m *mongoSessionStore) Delete(ctx context.Context, sessionId string) error {
+	if _, err := m.sessionColl.DeleteOne(ctx, bson.M{"_id": sessionId}); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (m *mongoSessionStore) DeleteForUser(ctx context.Context, username string) error {
+	if _, err := m.sessionColl.DeleteMany(ctx, bson.M{"username": username}); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (m *mongoSessionStore) DeleteExpired(ctx context.Context) (int64, error) {
+	result, err := m.sessionColl.DeleteMany(ctx, bson.M{"expiry": bson.M{"$lt": time.Now()}})
+	if err != nil {
+		return 0, err
+	}
+
+	return result.DeletedCount, nil
+}
@@ -0,0 +1,142 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/dspeirs7/animals/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type speciesRepository struct {
+	speciesColl *mongo.Collection
+}
+
+// NewSpeciesRepository indexes slugs and seeds the registry with
+// domain.DefaultSpecies the first time it runs.
+func NewSpeciesRepository(ctx context.Context, speciesColl *mongo.Collection) (domain.SpeciesRepository, error) {
+	index := mongo.IndexModel{
+		Keys:    bson.D{{Key: "slug", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	}
+
+	if _, err := speciesColl.Indexes().CreateOne(ctx, index); err != nil {
+		return nil, err
+	}
+
+	count, err := speciesColl.CountDocuments(ctx, bson.D{})
+	if err != nil {
+		return nil, err
+	}
+
+	if count == 0 {
+		docs := make([]interface{}, 0, len(domain.DefaultSpecies))
+		for _, species := range domain.DefaultSpecies {
+			docs = append(docs, species)
+		}
+
+		if _, err := speciesColl.InsertMany(ctx, docs); err != nil && !mongo.IsDuplicateKeyError(err) {
+			return nil, err
+		}
+	}
+
+	return &speciesRepository{speciesColl: speciesColl}, nil
+}
+
+func (m *speciesRepository) List(ctx context.Context, includeDisabled bool) ([]*domain.Species, error) {
+	filter := bson.M{}
+	if !includeDisabled {
+		filter["enabled"] = true
+	}
+
+	cursor, err := m.speciesColl.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+
+	results := []*domain.Species{}
+
+	if err = cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+func (m *speciesRepository) GetById(ctx context.Context, id domain.AnimalType) (*domain.Species, error) {
+	return m.findOne(ctx, bson.M{"_id": id})
+}
+
+func (m *speciesRepository) GetBySlug(ctx context.Context, slug string) (*domain.Species, error) {
+	return m.findOne(ctx, bson.M{"slug": slug})
+}
+
+func (m *speciesRepository) findOne(ctx context.Context, filter bson.M) (*domain.Species, error) {
+	var result domain.Species
+
+	if err := m.speciesColl.FindOne(ctx, filter).Decode(&result); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, domain.ErrSpeciesNotFound
+		}
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// Insert adds a type, numbering it after the highest existing id when it
+// doesn't bring its own.
+func (m *speciesRepository) Insert(ctx context.Context, species domain.Species) (*domain.Species, error) {
+	if species.Id == 0 {
+		var last domain.Species
+
+		opts := options.FindOne().SetSort(bson.D{{Key: "_id", Value: -1}})
+		if err := m.speciesColl.FindOne(ctx, bson.D{}, opts).Decode(&last); err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, err
+		}
+
+		species.Id = last.Id + 1
+	}
+
+	if _, err := m.speciesColl.InsertOne(ctx, species); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, domain.ErrSpeciesExists
+		}
+		return nil, err
+	}
+
+	return &species, nil
+}
+
+func (m *speciesRepository) Update(ctx context.Context, id domain.AnimalType, species domain.Species) error {
+	species.Id = id
+
+	result, err := m.speciesColl.ReplaceOne(ctx, bson.M{"_id": id}, species)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return domain.ErrSpeciesExists
+		}
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return domain.ErrSpeciesNotFound
+	}
+
+	return nil
+}
+
+func (m *speciesRepository) Delete(ctx context.Context, id domain.AnimalType) error {
+	result, err := m.speciesColl.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		return domain.ErrSpeciesNotFound
+	}
+
+	return nil
+}
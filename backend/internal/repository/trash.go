@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/dspeirs7/animals/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func (m *mongoAnimalRepository) Trash(ctx context.Context, id primitive.ObjectID, at time.Time, by string) error {
+	filter := bson.M{"_id": id, "deletedAt": bson.M{"$exists": false}}
+
+	set := bson.M{"deletedAt": at}
+	if by != "" {
+		set["deletedBy"] = by
+	}
+
+	result, err := m.animalColl.UpdateOne(ctx, filter, bson.M{"$set": set})
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return domain.ErrAnimalNotFound
+	}
+
+	return nil
+}
+
+func (m *mongoAnimalRepository) Restore(ctx context.Context, id primitive.ObjectID) error {
+	filter := bson.M{"_id": id, "deletedAt": bson.M{"$exists": true}}
+	change := bson.M{"$unset": bson.M{"deletedAt": "", "deletedBy": ""}}
+
+	result, err := m.animalColl.UpdateOne(ctx, filter, change)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return domain.ErrNotInTrash
+	}
+
+	return nil
+}
+
+// TrashedBefore lists the animals deleted before a time, the ones due to
+// be purged.
+func (m *mongoAnimalRepository) TrashedBefore(ctx context.Context, before time.Time) ([]*domain.Animal, error) {
+	cursor, err := m.animalColl.Find(ctx, bson.M{"deletedAt": bson.M{"$lt": before}})
+	if err != nil {
+		return nil, err
+	}
+
+	results := []*domain.Animal{}
+
+	if err = cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
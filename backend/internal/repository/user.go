@@ -2,10 +2,14 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"github.com/dspeirs7/animals/internal/domain"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type userRepository struct {
@@ -23,8 +27,115 @@ func (m *userRepository) GetUser(ctx context.Context, username string) (*domain.
 
 	cursor := m.userColl.FindOne(ctx, filter)
 	if err := cursor.Decode(&user); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return &domain.User{}, domain.ErrUserNotFound
+		}
 		return &domain.User{}, err
 	}
 
 	return &user, nil
 }
+
+func (m *userRepository) List(ctx context.Context) ([]*domain.User, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "username", Value: 1}})
+
+	cursor, err := m.userColl.Find(ctx, bson.D{}, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	users := []*domain.User{}
+
+	if err = cursor.All(ctx, &users); err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
+func (m *userRepository) Insert(ctx context.Context, user domain.User) (*domain.User, error) {
+	if user.CreatedAt.IsZero() {
+		user.CreatedAt = time.Now()
+	}
+
+	result, err := m.userColl.InsertOne(ctx, user)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, domain.ErrUserExists
+		}
+		return nil, err
+	}
+
+	user.Id = result.InsertedID.(primitive.ObjectID)
+
+	return &user, nil
+}
+
+func (m *userRepository) Update(ctx context.Context, username string, update domain.UserUpdate) error {
+	set := bson.M{}
+
+	if update.Role != nil {
+		set["role"] = *update.Role
+	}
+
+	if update.Disabled != nil {
+		set["disabled"] = *update.Disabled
+	}
+
+	if len(set) == 0 {
+		return nil
+	}
+
+	return m.updateOne(ctx, username, bson.M{"$set": set})
+}
+
+func (m *userRepository) SetPassword(ctx context.Context, username string, hashedPassword string) error {
+	return m.updateOne(ctx, username, bson.M{"$set": bson.M{"password": hashedPassword}})
+}
+
+func (m *userRepository) Delete(ctx context.Context, username string) error {
+	result, err := m.userColl.DeleteOne(ctx, bson.M{"username": username})
+	if err != nil {
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		return domain.ErrUserNotFound
+	}
+
+	return nil
+}
+
+func (m *userRepository) CountActiveAdmins(ctx context.Context) (int64, error) {
+	return m.userColl.CountDocuments(ctx, bson.M{"role": domain.RoleAdmin, "disabled": bson.M{"$ne": true}})
+}
+
+func (m *userRepository) GetByCalendarToken(ctx context.Context, tokenHash string) (*domain.User, error) {
+	var user domain.User
+
+	if err := m.userColl.FindOne(ctx, bson.M{"calendarToken": tokenHash}).Decode(&user); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, domain.ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+func (m *userRepository) SetCalendarToken(ctx context.Context, username string, tokenHash string) error {
+	return m.updateOne(ctx, username, bson.M{"$set": bson.M{"calendarToken": tokenHash}})
+}
+
+func (m *userRepository) updateOne(ctx context.Context, username string, change bson.M) error {
+	result, err := m.userColl.UpdateOne(ctx, bson.M{"username": username}, change)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return domain.ErrUserNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/dspeirs7/animals/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// pendingSortKey orders not-yet-given vaccinations after every given one so
+// they count as the latest record for their name.
+var pendingSortKey = time.Date(9999, time.December, 31, 0, 0, 0, 0, time.UTC)
+
+func (m *mongoAnimalRepository) VaccinationsDue(ctx context.Context, query domain.VaccinationDueQuery) ([]*domain.VaccinationDue, error) {
+	neededRange := bson.M{"$lt": query.To}
+	if !query.From.IsZero() {
+		neededRange["$gte"] = query.From
+	}
+
+	match := bson.M{
+		"vaccinations.dateNeeded": neededRange,
+		"status":                  statusFilter(domain.ActiveStatuses),
+		"deletedAt":               bson.M{"$exists": false},
+	}
+	if len(query.Types) > 0 {
+		match["type"] = bson.M{"$in": query.Types}
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$unwind", Value: "$vaccinations"}},
+		{{Key: "$addFields", Value: bson.M{
+			"latest": bson.M{"$ifNull": bson.A{"$vaccinations.dateGiven", pendingSortKey}},
+		}}},
+		{{Key: "$sort", Value: bson.D{
+			{Key: "_id", Value: 1},
+			{Key: "vaccinations.name", Value: 1},
+			{Key: "latest", Value: -1},
+			{Key: "vaccinations.dateNeeded", Value: -1},
+		}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":         bson.M{"animal": "$_id", "name": "$vaccinations.name"},
+			"animalName":  bson.M{"$first": "$name"},
+			"animalType":  bson.M{"$first": "$type"},
+			"vaccination": bson.M{"$first": "$vaccinations"},
+		}}},
+		{{Key: "$match", Value: bson.M{"vaccination.dateNeeded": neededRange}}},
+		{{Key: "$sort", Value: bson.D{{Key: "vaccination.dateNeeded", Value: 1}, {Key: "animalName", Value: 1}}}},
+		{{Key: "$project", Value: bson.M{
+			"_id":         0,
+			"animalId":    "$_id.animal",
+			"animalName":  1,
+			"animalType":  1,
+			"vaccination": 1,
+		}}},
+	}
+
+	cursor, err := m.animalColl.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+
+	results := []*domain.VaccinationDue{}
+
+	if err = cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/dspeirs7/animals/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func (m *mongoAnimalRepository) AddWeights(ctx context.Context, id string, weights []domain.Weight) error {
+	objectId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+
+	change := bson.M{"$push": bson.M{"weights": bson.M{"$each": weights}}}
+
+	if _, err := m.animalColl.UpdateByID(ctx, objectId, change); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (m *mongoAnimalRepository) DeleteWeight(ctx context.Context, id string, weightId primitive.ObjectID) error {
+	objectId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+
+	change := bson.M{"$pull": bson.M{"weights": bson.M{"id": weightId}}}
+
+	if _, err := m.animalColl.UpdateByID(ctx, objectId, change); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,61 @@
+package scheduler
+
+import (
+	"fmt"
+	"time"
+)
+
+type Schedule interface {
+	Next(after time.Time) time.Time
+	String() string
+}
+
+type every time.Duration
+
+// Every runs a job at a fixed interval.
+func Every(d time.Duration) Schedule {
+	return every(d)
+}
+
+func (e every) Next(after time.Time) time.Time {
+	return after.Add(time.Duration(e))
+}
+
+func (e every) String() string {
+	return "every " + time.Duration(e).String()
+}
+
+type daily struct {
+	hour, minute int
+	loc          *time.Location
+}
+
+// Daily runs a job once a day at the given wall clock time in loc.
+func Daily(hour, minute int, loc *time.Location) Schedule {
+	if loc == nil {
+		loc = time.Local
+	}
+	return daily{hour: hour, minute: minute, loc: loc}
+}
+
+func (d daily) Next(after time.Time) time.Time {
+	local := after.In(d.loc)
+	next := time.Date(local.Year(), local.Month(), local.Day(), d.hour, d.minute, 0, 0, d.loc)
+	if !next.After(local) {
+		next = time.Date(local.Year(), local.Month(), local.Day()+1, d.hour, d.minute, 0, 0, d.loc)
+	}
+	return next
+}
+
+func (d daily) String() string {
+	return fmt.Sprintf("daily at %02d:%02d %s", d.hour, d.minute, d.loc)
+}
+
+// ParseDaily parses a "15:04" time of day.
+func ParseDaily(clock string, loc *time.Location) (Schedule, error) {
+	t, err := time.Parse("15:04", clock)
+	if err != nil {
+		return nil, fmt.Errorf("invalid time of day %q: %w", clock, err)
+	}
+	return Daily(t.Hour(), t.Minute(), loc), nil
+}
@@ -0,0 +1,213 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+var (
+	ErrJobNotFound = errors.New("job not found")
+	ErrJobExists   = errors.New("job already registered")
+)
+
+type JobFunc func(ctx context.Context) error
+
+type JobStatus struct {
+	Name         string     `json:"name"`
+	Schedule     string     `json:"schedule"`
+	Running      bool       `json:"running"`
+	Runs         int        `json:"runs"`
+	Failures     int        `json:"failures"`
+	LastRun      *time.Time `json:"lastRun,omitempty"`
+	LastDuration string     `json:"lastDuration,omitempty"`
+	LastError    string     `json:"lastError,omitempty"`
+	NextRun      *time.Time `json:"nextRun,omitempty"`
+}
+
+type job struct {
+	name     string
+	schedule Schedule
+	run      JobFunc
+	trigger  chan struct{}
+	status   JobStatus
+}
+
+type Scheduler struct {
+	logger *zap.Logger
+
+	mu      sync.Mutex
+	jobs    map[string]*job
+	started bool
+	wg      sync.WaitGroup
+}
+
+func New(logger *zap.Logger) *Scheduler {
+	return &Scheduler{
+		logger: logger,
+		jobs:   make(map[string]*job),
+	}
+}
+
+// Register adds a job. Jobs must be registered before Start.
+func (s *Scheduler) Register(name string, schedule Schedule, run JobFunc) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.jobs[name]; ok {
+		return ErrJobExists
+	}
+
+	s.jobs[name] = &job{
+		name:     name,
+		schedule: schedule,
+		run:      run,
+		trigger:  make(chan struct{}, 1),
+		status:   JobStatus{Name: name, Schedule: schedule.String()},
+	}
+
+	return nil
+}
+
+// Start runs every job on its schedule until ctx is cancelled.
+func (s *Scheduler) Start(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.started {
+		return
+	}
+	s.started = true
+
+	for _, j := range s.jobs {
+		s.wg.Add(1)
+		go s.loop(ctx, j)
+	}
+
+	s.logger.Info("scheduler started", zap.Int("jobs", len(s.jobs)))
+}
+
+// Wait blocks until every job loop has returned after ctx was cancelled.
+func (s *Scheduler) Wait() {
+	s.wg.Wait()
+}
+
+// Trigger queues an immediate run of the named job.
+func (s *Scheduler) Trigger(name string) error {
+	s.mu.Lock()
+	j, ok := s.jobs[name]
+	s.mu.Unlock()
+
+	if !ok {
+		return ErrJobNotFound
+	}
+
+	select {
+	case j.trigger <- struct{}{}:
+	default:
+	}
+
+	return nil
+}
+
+// Run runs the named job now and waits for it, for one-off commands that
+// don't Start the scheduler.
+func (s *Scheduler) Run(ctx context.Context, name string) error {
+	s.mu.Lock()
+	j, ok := s.jobs[name]
+	s.mu.Unlock()
+
+	if !ok {
+		return ErrJobNotFound
+	}
+
+	return s.execute(ctx, j)
+}
+
+func (s *Scheduler) Status() []JobStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	statuses := make([]JobStatus, 0, len(s.jobs))
+	for _, j := range s.jobs {
+		statuses = append(statuses, j.status)
+	}
+
+	sort.Slice(statuses, func(i, k int) bool { return statuses[i].Name < statuses[k].Name })
+
+	return statuses
+}
+
+func (s *Scheduler) loop(ctx context.Context, j *job) {
+	defer s.wg.Done()
+
+	for {
+		next := j.schedule.Next(time.Now())
+
+		s.mu.Lock()
+		j.status.NextRun = &next
+		s.mu.Unlock()
+
+		timer := time.NewTimer(time.Until(next))
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-j.trigger:
+			timer.Stop()
+		case <-timer.C:
+		}
+
+		s.execute(ctx, j)
+	}
+}
+
+func (s *Scheduler) execute(ctx context.Context, j *job) error {
+	start := time.Now()
+
+	s.mu.Lock()
+	j.status.Running = true
+	s.mu.Unlock()
+
+	s.logger.Info("job started", zap.String("job", j.name))
+
+	err := safeRun(ctx, j.run)
+	duration := time.Since(start)
+
+	s.mu.Lock()
+	j.status.Running = false
+	j.status.Runs++
+	j.status.LastRun = &start
+	j.status.LastDuration = duration.String()
+	j.status.LastError = ""
+	if err != nil {
+		j.status.Failures++
+		j.status.LastError = err.Error()
+	}
+	s.mu.Unlock()
+
+	if err != nil {
+		s.logger.Error("job failed", zap.String("job", j.name), zap.Duration("duration", duration), zap.Error(err))
+		return err
+	}
+
+	s.logger.Info("job finished", zap.String("job", j.name), zap.Duration("duration", duration))
+
+	return nil
+}
+
+func safeRun(ctx context.Context, run JobFunc) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+
+	return run(ctx)
+}
@@ -0,0 +1,192 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func TestEveryNext(t *testing.T) {
+	after := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		every time.Duration
+		want  time.Time
+	}{
+		{time.Minute, after.Add(time.Minute)},
+		{15 * time.Minute, after.Add(15 * time.Minute)},
+		{24 * time.Hour, after.Add(24 * time.Hour)},
+	}
+
+	for _, tt := range tests {
+		if got := Every(tt.every).Next(after); !got.Equal(tt.want) {
+			t.Errorf("Every(%s).Next = %s, want %s", tt.every, got, tt.want)
+		}
+	}
+}
+
+func TestDailyNext(t *testing.T) {
+	chicago, err := time.LoadLocation("America/Chicago")
+	if err != nil {
+		t.Skip("time zone data unavailable:", err)
+	}
+
+	tests := []struct {
+		name  string
+		clock string
+		after time.Time
+		want  time.Time
+	}{
+		{"later today", "07:00", time.Date(2024, 5, 1, 6, 0, 0, 0, chicago), time.Date(2024, 5, 1, 7, 0, 0, 0, chicago)},
+		{"exactly now runs tomorrow", "07:00", time.Date(2024, 5, 1, 7, 0, 0, 0, chicago), time.Date(2024, 5, 2, 7, 0, 0, 0, chicago)},
+		{"already passed", "07:00", time.Date(2024, 5, 1, 18, 30, 0, 0, chicago), time.Date(2024, 5, 2, 7, 0, 0, 0, chicago)},
+		{"after is in another zone", "07:00", time.Date(2024, 5, 1, 11, 0, 0, 0, time.UTC), time.Date(2024, 5, 1, 7, 0, 0, 0, chicago)},
+		{"month end", "23:45", time.Date(2024, 1, 31, 23, 50, 0, 0, chicago), time.Date(2024, 2, 1, 23, 45, 0, 0, chicago)},
+		{"across daylight saving", "07:00", time.Date(2024, 3, 9, 8, 0, 0, 0, chicago), time.Date(2024, 3, 10, 7, 0, 0, 0, chicago)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule, err := ParseDaily(tt.clock, chicago)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := schedule.Next(tt.after); !got.Equal(tt.want) {
+				t.Errorf("Next(%s) = %s, want %s", tt.after, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseDaily(t *testing.T) {
+	tests := []struct {
+		clock   string
+		wantErr bool
+	}{
+		{"07:00", false},
+		{"23:59", false},
+		{"7:00", false},
+		{"24:00", true},
+		{"07:60", true},
+		{"seven", true},
+		{"", true},
+	}
+
+	for _, tt := range tests {
+		_, err := ParseDaily(tt.clock, time.UTC)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseDaily(%q) error = %v, wantErr %v", tt.clock, err, tt.wantErr)
+		}
+	}
+}
+
+func TestRegister(t *testing.T) {
+	s := New(zap.NewNop())
+
+	if err := s.Register("job", Every(time.Hour), func(context.Context) error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.Register("job", Every(time.Hour), func(context.Context) error { return nil }); !errors.Is(err, ErrJobExists) {
+		t.Errorf("duplicate Register error = %v, want ErrJobExists", err)
+	}
+
+	if err := s.Trigger("missing"); !errors.Is(err, ErrJobNotFound) {
+		t.Errorf("Trigger(missing) error = %v, want ErrJobNotFound", err)
+	}
+}
+
+func TestTrigger(t *testing.T) {
+	tests := []struct {
+		name        string
+		run         func(context.Context) error
+		wantFailure bool
+		wantError   string
+	}{
+		{"success", func(context.Context) error { return nil }, false, ""},
+		{"error", func(context.Context) error { return errors.New("boom") }, true, "boom"},
+		{"panic", func(context.Context) error { panic("oops") }, true, "job panicked: oops"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := New(zap.NewNop())
+
+			done := make(chan struct{}, 1)
+			run := func(ctx context.Context) error {
+				defer func() { done <- struct{}{} }()
+				return tt.run(ctx)
+			}
+
+			if err := s.Register("job", Every(time.Hour), run); err != nil {
+				t.Fatal(err)
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			s.Start(ctx)
+
+			if err := s.Trigger("job"); err != nil {
+				t.Fatal(err)
+			}
+
+			select {
+			case <-done:
+			case <-time.After(5 * time.Second):
+				t.Fatal("triggered job did not run")
+			}
+
+			cancel()
+			s.Wait()
+
+			status := s.Status()
+			if len(status) != 1 {
+				t.Fatalf("got %d statuses, want 1", len(status))
+			}
+
+			got := status[0]
+			if got.Runs != 1 || got.Running || got.LastRun == nil {
+				t.Errorf("status = %+v, want one finished run", got)
+			}
+			if (got.Failures == 1) != tt.wantFailure {
+				t.Errorf("failures = %d, wantFailure %v", got.Failures, tt.wantFailure)
+			}
+			if got.LastError != tt.wantError {
+				t.Errorf("last error = %q, want %q", got.LastError, tt.wantError)
+			}
+		})
+	}
+}
+
+func TestRun(t *testing.T) {
+	s := New(zap.NewNop())
+
+	runs := 0
+	if err := s.Register("job", Every(time.Hour), func(context.Context) error {
+		runs++
+		if runs == 2 {
+			return errors.New("boom")
+		}
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.Run(context.Background(), "job"); err != nil {
+		t.Errorf("first Run error = %v", err)
+	}
+	if err := s.Run(context.Background(), "job"); err == nil || err.Error() != "boom" {
+		t.Errorf("second Run error = %v, want boom", err)
+	}
+	if err := s.Run(context.Background(), "missing"); !errors.Is(err, ErrJobNotFound) {
+		t.Errorf("Run(missing) error = %v, want ErrJobNotFound", err)
+	}
+
+	status := s.Status()[0]
+	if runs != 2 || status.Runs != 2 || status.Failures != 1 || status.LastError != "boom" {
+		t.Errorf("after two runs: runs = %d, status = %+v", runs, status)
+	}
+}
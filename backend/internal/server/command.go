@@ -0,0 +1,65 @@
+package server
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/dspeirs7/animals/internal/api"
+	"github.com/dspeirs7/animals/internal/log"
+	"go.uber.org/zap"
+)
+
+// RunCommand runs a one-off admin command against the configured database
+// and image store instead of starting the server.
+func RunCommand(args []string) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Kill, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	logger := log.NewLogger("command")
+	defer logger.Sync()
+
+	api := api.NewAPI(ctx, logger)
+	defer api.Disconnect(context.Background())
+
+	switch args[0] {
+	case "reprocess-images":
+		flags := flag.NewFlagSet(args[0], flag.ExitOnError)
+		force := flags.Bool("force", false, "regenerate variants for images that already have them")
+		flags.Parse(args[1:])
+
+		processed, err := api.ReprocessImages(ctx, *force)
+		if err != nil {
+			logger.Fatal("reprocessing images failed", zap.Int("processed", processed), zap.Error(err))
+		}
+
+		logger.Info("images reprocessed", zap.Int("processed", processed))
+	case "cleanup-images":
+		grace, err := api.ImageGracePeriod()
+		if err != nil {
+			logger.Fatal("invalid IMAGE_GRACE_PERIOD", zap.Error(err))
+		}
+
+		flags := flag.NewFlagSet(args[0], flag.ExitOnError)
+		flags.DurationVar(&grace, "grace", grace, "only remove unreferenced images older than this")
+		flags.Parse(args[1:])
+
+		removed, err := api.CleanupImages(ctx, grace)
+		if err != nil {
+			logger.Fatal("cleaning up images failed", zap.Int("removed", removed), zap.Error(err))
+		}
+
+		logger.Info("orphaned images removed", zap.Int("removed", removed))
+	case "purge-trash":
+		// Runs the scheduled trash-purge job, which reads TRASH_RETENTION.
+		if err := api.RunJob(ctx, "trash-purge"); err != nil {
+			logger.Fatal("purging trash failed", zap.Error(err))
+		}
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n", args[0])
+		os.Exit(2)
+	}
+}
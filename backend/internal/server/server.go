@@ -33,6 +33,8 @@ func StartServer() {
 
 	logger.Info("server started", zap.Int("port", port))
 
+	api.StartJobs(ctx)
+
 	<-ctx.Done()
 	stop()
 
@@ -41,6 +43,8 @@ func StartServer() {
 	shutdownCtx, shutdownStop := context.WithTimeout(context.Background(), 10*time.Second)
 	defer shutdownStop()
 
+	api.WaitJobs()
+
 	if err := api.Disconnect(shutdownCtx); err != nil {
 		logger.Fatal("couldn't disconnect from db", zap.Error(err))
 	}
@@ -0,0 +1,180 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/dspeirs7/animals/internal/domain"
+)
+
+type localStore struct {
+	dir string
+}
+
+func NewLocalStore(dir string) (domain.ImageStore, error) {
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return nil, err
+	}
+
+	return &localStore{dir: dir}, nil
+}
+
+func (s *localStore) path(key string) (string, error) {
+	key, err := cleanKey(key)
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(s.dir, filepath.FromSlash(key)), nil
+}
+
+func (s *localStore) Put(_ context.Context, key string, r io.Reader, _ int64, _ string) error {
+	dst, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dst), os.ModePerm); err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so readers never see a partial image.
+	tmp, err := os.CreateTemp(filepath.Dir(dst), ".upload-*")
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	return os.Rename(tmp.Name(), dst)
+}
+
+func (s *localStore) Get(ctx context.Context, key string) (io.ReadCloser, *domain.ImageInfo, error) {
+	src, err := s.path(key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	info, err := s.Stat(ctx, key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	file, err := os.Open(src)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil, domain.ErrImageNotFound
+	} else if err != nil {
+		return nil, nil, err
+	}
+
+	return file, info, nil
+}
+
+func (s *localStore) Stat(_ context.Context, key string) (*domain.ImageInfo, error) {
+	src, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	stat, err := os.Stat(src)
+	if errors.Is(err, os.ErrNotExist) || errors.Is(err, syscall.ENOTDIR) {
+		return nil, domain.ErrImageNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	if stat.IsDir() {
+		return nil, domain.ErrImageNotFound
+	}
+
+	return &domain.ImageInfo{
+		Key:         key,
+		ContentType: mime.TypeByExtension(path.Ext(key)),
+		Size:        stat.Size(),
+		ModTime:     stat.ModTime(),
+	}, nil
+}
+
+func (s *localStore) List(ctx context.Context) ([]domain.ImageInfo, error) {
+	var images []domain.ImageInfo
+
+	err := filepath.WalkDir(s.dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		// Skip in-flight uploads.
+		if d.IsDir() || strings.HasPrefix(d.Name(), ".upload-") {
+			return nil
+		}
+
+		rel, err := filepath.Rel(s.dir, p)
+		if err != nil {
+			return err
+		}
+
+		stat, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		key := filepath.ToSlash(rel)
+		images = append(images, domain.ImageInfo{
+			Key:         key,
+			ContentType: mime.TypeByExtension(path.Ext(key)),
+			Size:        stat.Size(),
+			ModTime:     stat.ModTime(),
+		})
+
+		return nil
+	})
+
+	return images, err
+}
+
+func (s *localStore) Delete(_ context.Context, key string) error {
+	dst, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(dst); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
+
+func (s *localStore) URL(key string) string {
+	return defaultURLPrefix + "/" + key
+}
+
+func (s *localStore) Key(url string) string {
+	return keyFromURL(defaultURLPrefix, url)
+}
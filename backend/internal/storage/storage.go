@@ -0,0 +1,44 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/dspeirs7/animals/internal/domain"
+)
+
+const defaultURLPrefix = "images"
+
+var ErrInvalidKey = errors.New("invalid image key")
+
+// NewImageStoreFromEnv picks the image store from IMAGE_STORE ("local" or
+// "s3"), defaulting to the local ./images directory.
+func NewImageStoreFromEnv() (domain.ImageStore, error) {
+	switch store := os.Getenv("IMAGE_STORE"); store {
+	case "", "local":
+		dir := os.Getenv("IMAGE_DIR")
+		if dir == "" {
+			dir = "images"
+		}
+		return NewLocalStore(dir)
+	case "s3":
+		return NewS3StoreFromEnv()
+	default:
+		return nil, fmt.Errorf("unknown IMAGE_STORE %q", store)
+	}
+}
+
+func cleanKey(key string) (string, error) {
+	key = strings.TrimPrefix(key, "/")
+	if key == "" || path.Clean(key) != key || strings.HasPrefix(key, "../") || key == ".." {
+		return "", ErrInvalidKey
+	}
+	return key, nil
+}
+
+func keyFromURL(prefix, url string) string {
+	return strings.TrimPrefix(strings.TrimPrefix(url, prefix), "/")
+}
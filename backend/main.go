@@ -2,6 +2,7 @@ package main
 
 import (
 	"log"
+	"os"
 
 	"github.com/dspeirs7/animals/internal/server"
 	"github.com/joho/godotenv"
@@ -12,5 +13,10 @@ func main() {
 		log.Println("No .env file found")
 	}
 
+	if len(os.Args) > 1 {
+		server.RunCommand(os.Args[1:])
+		return
+	}
+
 	server.StartServer()
 }
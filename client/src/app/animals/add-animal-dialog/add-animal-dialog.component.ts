@@ -10,6 +10,9 @@ import { MatButtonModule } from '@angular/material/button';
 import { MatInputModule } from '@angular/material/input';
 import { MatSelectModule } from '@angular/material/select';
 import { MAT_DIALOG_DATA, MatDialogModule } from '@angular/material/dialog';
+import { Observable } from 'rxjs';
+import { AnimalType, Breed } from 'src/app/models/animal';
+import { CatalogService } from '../catalog.service';
 
 interface AddChickenForm {
   name: FormControl<string>;
@@ -19,7 +22,7 @@ interface AddChickenForm {
 }
 
 interface DialogData {
-  animalType: 1 | 2 | 3;
+  animalType: AnimalType;
 }
 
 @Component({
@@ -37,15 +40,21 @@ interface DialogData {
   styleUrls: ['./add-animal-dialog.component.scss'],
 })
 export class AddAnimalDialogComponent {
-  constructor(@Inject(MAT_DIALOG_DATA) public data: DialogData) {}
+  constructor(
+    @Inject(MAT_DIALOG_DATA) public data: DialogData,
+    private catalogService: CatalogService
+  ) {}
 
   addAnimalForm: FormGroup;
+  breeds: Observable<Breed[]>;
 
   ngOnInit(): void {
+    this.breeds = this.catalogService.getBreeds(this.data.animalType.id);
+
     this.addAnimalForm = new FormGroup<AddChickenForm>({
       name: new FormControl<string>('', { nonNullable: true }),
       description: new FormControl(),
-      type: new FormControl<number>(this.data.animalType, {
+      type: new FormControl<number>(this.data.animalType.id, {
         nonNullable: true,
       }),
       breed: new FormControl<number>(0, {
@@ -18,8 +18,10 @@ import {
 } from '@angular/material/dialog';
 import { EnvironmentPipe } from '../../environment.pipe';
 import { AuthService } from '../../login/auth.service';
-import { Animal, AnimalName } from 'src/app/models/animal';
+import { Observable } from 'rxjs';
+import { Animal } from 'src/app/models/animal';
 import { AnimalService } from '../animal.service';
+import { CatalogService } from '../catalog.service';
 
 @Component({
   selector: 'app-animal-card',
@@ -37,19 +39,21 @@ import { AnimalService } from '../animal.service';
 })
 export class AnimalCardComponent {
   @Input() animal: Animal;
-  @Input() type: AnimalName;
   @Input() showActions: boolean = false;
   @Output() onDelete = new EventEmitter<string>();
   isLoggedIn: Signal<boolean>;
+  breedName: Observable<string>;
 
   constructor(
     private authService: AuthService,
     private animalService: AnimalService,
+    private catalogService: CatalogService,
     private matDialog: MatDialog
   ) {}
 
   ngOnInit(): void {
     this.isLoggedIn = this.authService.isLoggedIn();
+    this.breedName = this.catalogService.getBreedName(this.animal.breed);
   }
 
   deleteAnimal(animal: Animal) {
@@ -1,8 +1,8 @@
-import { HttpClient } from '@angular/common/http';
+import { HttpClient, HttpParams } from '@angular/common/http';
 import { Injectable } from '@angular/core';
-import { Observable } from 'rxjs';
+import { Observable, map } from 'rxjs';
 import { environment } from 'src/environments/environment';
-import { Animal, Vaccination } from '../models/animal';
+import { Animal, AnimalPage, Vaccination } from '../models/animal';
 
 @Injectable({
   providedIn: 'root',
@@ -10,16 +10,21 @@ import { Animal, Vaccination } from '../models/animal';
 export class AnimalService {
   constructor(private http: HttpClient) {}
 
-  getCats() {
-    return this.http.get<Animal[]>(`${environment.apiUrl}/cats`);
-  }
-
-  getChickens() {
-    return this.http.get<Animal[]>(`${environment.apiUrl}/chickens`);
-  }
+  getTypeAnimals(slug: string, cursor?: string): Observable<AnimalPage> {
+    const params = cursor ? new HttpParams().set('cursor', cursor) : undefined;
 
-  getDogs() {
-    return this.http.get<Animal[]>(`${environment.apiUrl}/dogs`);
+    return this.http
+      .get<Animal[]>(`${environment.apiUrl}/types/${slug}/animals`, {
+        params,
+        observe: 'response',
+      })
+      .pipe(
+        map((response) => ({
+          animals: response.body ?? [],
+          total: Number(response.headers.get('X-Total-Count') ?? 0),
+          nextCursor: response.headers.get('X-Next-Cursor') ?? undefined,
+        }))
+      );
   }
 
   getAnimal(id: string) {
@@ -3,12 +3,11 @@ import {
   DestroyRef,
   OnInit,
   Signal,
-  computed,
   inject,
   signal,
 } from '@angular/core';
 import { CommonModule } from '@angular/common';
-import { filter, of, switchMap } from 'rxjs';
+import { filter, map, of, switchMap, tap } from 'rxjs';
 import { HttpClientModule } from '@angular/common/http';
 import { MatButtonModule } from '@angular/material/button';
 import { MatIconModule } from '@angular/material/icon';
@@ -17,7 +16,8 @@ import { ActivatedRoute, Router, RouterModule } from '@angular/router';
 import { AuthService } from '../login/auth.service';
 import { AnimalService } from '../animals/animal.service';
 import { AnimalCardComponent } from '../animals/animal-card/animal-card.component';
-import { Animal, AnimalName, AnimalType } from '../models/animal';
+import { CatalogService } from '../animals/catalog.service';
+import { Animal, AnimalPage, AnimalType } from '../models/animal';
 import { AddAnimalDialogComponent } from '../animals/add-animal-dialog/add-animal-dialog.component';
 import { takeUntilDestroyed } from '@angular/core/rxjs-interop';
 
@@ -38,27 +38,16 @@ import { takeUntilDestroyed } from '@angular/core/rxjs-interop';
 })
 export class AnimalsComponent implements OnInit {
   isLoggedIn: Signal<boolean>;
-  animalType = signal<AnimalType>(1);
-  animalTypeName = computed<AnimalName>(() => {
-    const type = this.animalType();
-    switch (type) {
-      case 1:
-        return 'Cat';
-      case 2:
-        return 'Chicken';
-      case 3:
-        return 'Dog';
-      default:
-        return 'Cat';
-    }
-  });
+  animalType = signal<AnimalType | undefined>(undefined);
   animals = signal<Animal[]>([]);
+  nextCursor = signal<string | undefined>(undefined);
   destroyRef = inject(DestroyRef);
 
   constructor(
     private route: ActivatedRoute,
     private authService: AuthService,
     private animalService: AnimalService,
+    private catalogService: CatalogService,
     private matDialog: MatDialog,
     private router: Router
   ) {}
@@ -66,27 +55,36 @@ export class AnimalsComponent implements OnInit {
   ngOnInit(): void {
     this.isLoggedIn = this.authService.isLoggedIn();
 
-    this.route.data
+    this.route.paramMap
       .pipe(
         takeUntilDestroyed(this.destroyRef),
-        switchMap((data) => {
-          const animalType = data['animalType'];
-          this.animalType.set(animalType);
+        map((params) => params.get('type') ?? ''),
+        switchMap((slug) => this.catalogService.getType(slug)),
+        tap((animalType) => this.animalType.set(animalType)),
+        switchMap((animalType) =>
+          animalType
+            ? this.animalService.getTypeAnimals(animalType.slug)
+            : of({ animals: [], total: 0 } as AnimalPage)
+        )
+      )
+      .subscribe((page) => {
+        this.animals.set(page.animals);
+        this.nextCursor.set(page.nextCursor);
+      });
+  }
 
-          switch (animalType) {
-            case 1:
-              return this.animalService.getCats();
-            case 2:
-              return this.animalService.getChickens();
-            case 3:
-              return this.animalService.getDogs();
-          }
+  loadMore() {
+    const animalType = this.animalType();
+    const cursor = this.nextCursor();
+    if (!animalType || !cursor) {
+      return;
+    }
 
-          return of([]);
-        })
-      )
-      .subscribe((animals) => {
-        this.animals.set(animals || []);
+    this.animalService
+      .getTypeAnimals(animalType.slug, cursor)
+      .subscribe((page) => {
+        this.animals.set([...this.animals(), ...page.animals]);
+        this.nextCursor.set(page.nextCursor);
       });
   }
 
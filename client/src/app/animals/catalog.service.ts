@@ -0,0 +1,46 @@
+import { HttpClient } from '@angular/common/http';
+import { Injectable } from '@angular/core';
+import { Observable, map, shareReplay } from 'rxjs';
+import { environment } from 'src/environments/environment';
+import { AnimalType, Breed } from '../models/animal';
+
+@Injectable({
+  providedIn: 'root',
+})
+export class CatalogService {
+  private types: Observable<AnimalType[]>;
+  private breeds: Observable<Breed[]>;
+
+  constructor(private http: HttpClient) {
+    this.types = this.http
+      .get<AnimalType[]>(`${environment.apiUrl}/types`)
+      .pipe(shareReplay(1));
+    this.breeds = this.http
+      .get<Breed[]>(`${environment.apiUrl}/breeds`)
+      .pipe(shareReplay(1));
+  }
+
+  getTypes() {
+    return this.types;
+  }
+
+  getType(slug: string) {
+    return this.types.pipe(
+      map((types) => types.find((type) => type.slug === slug))
+    );
+  }
+
+  getBreeds(type?: number) {
+    return this.breeds.pipe(
+      map((breeds) =>
+        type ? breeds.filter((breed) => breed.type === type) : breeds
+      )
+    );
+  }
+
+  getBreedName(id: number) {
+    return this.breeds.pipe(
+      map((breeds) => breeds.find((breed) => breed.id === id)?.name ?? '')
+    );
+  }
+}
@@ -16,8 +16,10 @@ import {
 import { MatButtonModule } from '@angular/material/button';
 import { MatIconModule } from '@angular/material/icon';
 import { MatToolbarModule } from '@angular/material/toolbar';
-import { filter, map } from 'rxjs';
+import { Observable, filter } from 'rxjs';
 import { AuthService } from './login/auth.service';
+import { CatalogService } from './animals/catalog.service';
+import { AnimalType } from './models/animal';
 import { takeUntilDestroyed } from '@angular/core/rxjs-interop';
 
 @Component({
@@ -37,12 +39,14 @@ import { takeUntilDestroyed } from '@angular/core/rxjs-interop';
 export class AppComponent implements OnInit {
   isLoggedIn: Signal<boolean>;
   showBack = signal<boolean>(false);
+  types: Observable<AnimalType[]>;
   destroyRef = inject(DestroyRef);
 
   constructor(
     private location: Location,
     private router: Router,
-    private authService: AuthService
+    private authService: AuthService,
+    private catalogService: CatalogService
   ) {
     this.router.events
       .pipe(
@@ -50,16 +54,14 @@ export class AppComponent implements OnInit {
         filter((event) => event instanceof NavigationEnd)
       )
       .subscribe((event) => {
-        this.showBack.set(
-          !['/cats', '/chickens', '/dogs'].includes(
-            (event as NavigationEnd).urlAfterRedirects
-          )
-        );
+        const url = (event as NavigationEnd).urlAfterRedirects;
+        this.showBack.set(url === '/login' || url.startsWith('/animal/'));
       });
   }
 
   ngOnInit(): void {
     this.isLoggedIn = this.authService.isLoggedIn();
+    this.types = this.catalogService.getTypes();
   }
 
   logout() {
@@ -7,24 +7,6 @@ export const routes: Routes = [
     loadComponent: () =>
       import('./login/login.component').then((mod) => mod.LoginComponent),
   },
-  {
-    path: 'cats',
-    data: { animalType: 1 },
-    loadComponent: () =>
-      import('./animals/animals.component').then((mod) => mod.AnimalsComponent),
-  },
-  {
-    path: 'chickens',
-    data: { animalType: 2 },
-    loadComponent: () =>
-      import('./animals/animals.component').then((mod) => mod.AnimalsComponent),
-  },
-  {
-    path: 'dogs',
-    data: { animalType: 3 },
-    loadComponent: () =>
-      import('./animals/animals.component').then((mod) => mod.AnimalsComponent),
-  },
   {
     path: 'animal/:animalId',
     loadComponent: () =>
@@ -32,6 +14,11 @@ export const routes: Routes = [
         (mod) => mod.AnimalComponent
       ),
   },
+  {
+    path: ':type',
+    loadComponent: () =>
+      import('./animals/animals.component').then((mod) => mod.AnimalsComponent),
+  },
   {
     path: '**',
     redirectTo: 'cats',
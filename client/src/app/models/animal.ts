@@ -3,35 +3,35 @@ export interface Animal {
   name: string;
   description: string;
   imageUrl: string;
-  type: AnimalType;
-  breed: CatBreed | ChickenBreed | DogBreed;
+  type: number;
+  breed: number;
   vaccinations: Vaccination[];
 }
 
+export interface AnimalPage {
+  animals: Animal[];
+  total: number;
+  nextCursor?: string;
+}
+
 export interface Vaccination {
   name: string;
   dateGiven: Date;
   dateNeeded: Date;
 }
 
-export enum AnimalType {
-  Cat = 1,
-  Chicken = 2,
-  Dog = 3,
-}
-
-export enum CatBreed {
-  RussianBlue = 1,
-}
-
-export enum ChickenBreed {
-  Brahma = 10,
-  BuffOrpington = 11,
+export interface AnimalType {
+  id: number;
+  slug: string;
+  name: string;
+  plural: string;
+  icon?: string;
+  enabled: boolean;
+  laysEggs?: boolean;
 }
 
-export enum DogBreed {
-  Mix = 20,
-  FoxHound = 21,
+export interface Breed {
+  id: number;
+  type: number;
+  name: string;
 }
-
-export type AnimalName = 'Cat' | 'Chicken' | 'Dog';